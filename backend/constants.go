@@ -22,6 +22,10 @@ var ErrInputNotSet = errors.New("variable is not allocated")
 // ErrUnsatisfiedConstraint can be generated when solving a R1CS
 var ErrUnsatisfiedConstraint = errors.New("constraint is not satisfied")
 
+// ErrSecretInPublicWitness can be generated when running a Verifier, or reading a public witness,
+// with secret values
+var ErrSecretInPublicWitness = errors.New("public witness contains secret values")
+
 // note: this types are shared between frontend and backend packages and are here to avoid import cycles
 // probably need a better naming / home for them
 
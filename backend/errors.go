@@ -0,0 +1,77 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnsatisfiedConstraintError is returned when solving a R1CS if one of its constraints doesn't hold
+//
+// errors.Is(err, ErrUnsatisfiedConstraint) holds for it
+type UnsatisfiedConstraintError struct {
+	ConstraintID int            // index of the constraint in the R1CS (the assertions come after the computational constraints)
+	Assertion    bool           // true if the constraint is an assertion, false if it is a computational constraint
+	DebugInfo    string         // resolved debug info of the assertion, empty if it was stripped at compile time
+	L, R, O      string         // values of the linear expressions of the constraint (L * R != O)
+	Wires        map[int]string // values of the wires involved in the constraint, "<unsolved>" if not computed
+	Stack        []string       // call stack where the constraint was added, if recorded at compile time
+	Component    string         // path of the component the constraint belongs to, if any
+}
+
+func (e *UnsatisfiedConstraintError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrUnsatisfiedConstraint.Error())
+	sb.WriteString(": ")
+	switch {
+	case !e.Assertion:
+		fmt.Fprintf(&sb, "computational constraint #%d (%s*%s=%s)", e.ConstraintID, e.L, e.R, e.O)
+	case e.DebugInfo != "":
+		sb.WriteString(e.DebugInfo)
+	default:
+		fmt.Fprintf(&sb, "assertion, constraint #%d (%s*%s=%s)", e.ConstraintID, e.L, e.R, e.O)
+	}
+	for i := 0; i < len(e.Stack); i++ {
+		sb.WriteString("\n")
+		sb.WriteString(e.Stack[i])
+	}
+	if e.Component != "" {
+		sb.WriteString("\nin component ")
+		sb.WriteString(e.Component)
+	}
+	return sb.String()
+}
+
+// Unwrap returns ErrUnsatisfiedConstraint
+func (e *UnsatisfiedConstraintError) Unwrap() error {
+	return ErrUnsatisfiedConstraint
+}
+
+// WireValues returns the values of the wires involved in the constraint, sorted by wire ID,
+// in a "wire #id = value" format
+func (e *UnsatisfiedConstraintError) WireValues() []string {
+	ids := make([]int, 0, len(e.Wires))
+	for id := range e.Wires {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = fmt.Sprintf("wire #%d = %s", id, e.Wires[id])
+	}
+	return res
+}
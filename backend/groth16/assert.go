@@ -16,37 +16,137 @@ package groth16
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"reflect"
 	"testing"
 
 	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
 	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gurvy"
 	"github.com/stretchr/testify/require"
 )
 
 // Assert is a helper to test circuits
 type Assert struct {
+	t *testing.T
 	*require.Assertions
 }
 
 // NewAssert returns an Assert helper
 func NewAssert(t *testing.T) *Assert {
-	return &Assert{require.New(t)}
+	return &Assert{t, require.New(t)}
 }
 
-// ProverFailed check that a solution does NOT solve a circuit
+// CheckOption tunes the behavior of Assert.CheckCircuit
+type CheckOption func(config *checkConfig)
+
+type checkConfig struct {
+	curves             []gurvy.ID
+	validAssignments   []interface{}
+	invalidAssignments []invalidAssignment
+	compileOptions     []frontend.CompileOption
+}
+
+type invalidAssignment struct {
+	assignment  interface{}
+	expectedErr []error
+}
+
+// WithCurves sets the curves the circuit is checked on (by default, all the supported curves)
+func WithCurves(curves ...gurvy.ID) CheckOption {
+	return func(config *checkConfig) {
+		config.curves = curves
+	}
+}
+
+// WithValidAssignment adds an assignment which must be proven and verified
+//
+// assignment must be map[string]interface{} or must implement frontend.Circuit
+// ( see frontend.ParseWitness )
+func WithValidAssignment(assignment interface{}) CheckOption {
+	return func(config *checkConfig) {
+		config.validAssignments = append(config.validAssignments, assignment)
+	}
+}
+
+// WithInvalidAssignment adds an assignment which must fail to be solved; if expectedErr is given,
+// the error must match it (see errors.Is), for example backend.ErrInputNotSet or backend.ErrUnsatisfiedConstraint
+func WithInvalidAssignment(assignment interface{}, expectedErr ...error) CheckOption {
+	return func(config *checkConfig) {
+		config.invalidAssignments = append(config.invalidAssignments, invalidAssignment{assignment, expectedErr})
+	}
+}
+
+// WithCompileOptions sets the options given to frontend.Compile
+func WithCompileOptions(opts ...frontend.CompileOption) CheckOption {
+	return func(config *checkConfig) {
+		config.compileOptions = opts
+	}
+}
+
+// CheckCircuit compiles circuit on each curve (see WithCurves) and, in a sub-test per curve:
+//
+// 1. runs ProverSucceeded and the test engine (see frontend.IsSolved) on the valid assignments
+//
+// 2. runs ProverFailed and the test engine on the invalid assignments
+//
+// 3. ensures deserialization(serialization) of the R1CS is correct
+//
+// the failures on all the curves are reported together. In short mode (see testing.Short), the
+// circuit is only checked on the first curve
+func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...CheckOption) {
+	config := checkConfig{
+		curves: []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761},
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if testing.Short() && len(config.curves) > 1 {
+		config.curves = config.curves[:1]
+	}
+
+	for _, curveID := range config.curves {
+		curveID := curveID
+		assert.t.Run(curveID.String(), func(t *testing.T) {
+			assert := NewAssert(t)
+
+			// Compile allocates the inputs in the circuit struct, it is compiled from a copy
+			_r1cs, err := frontend.Compile(curveID, copyCircuit(circuit), config.compileOptions...)
+			assert.NoError(err)
+
+			for _, valid := range config.validAssignments {
+				assert.NoError(frontend.IsSolved(copyCircuit(circuit), valid, curveID), "the test engine should solve a valid assignment")
+				assert.ProverSucceeded(_r1cs, valid)
+			}
+
+			for _, invalid := range config.invalidAssignments {
+				err := frontend.IsSolved(copyCircuit(circuit), invalid.assignment, curveID)
+				assert.Error(err, "the test engine should not solve an invalid assignment")
+				assert.expectedError(err, invalid.expectedErr)
+				assert.ProverFailed(_r1cs, invalid.assignment, invalid.expectedErr...)
+			}
+
+			assert.serializationSucceeded(_r1cs, r1cs.New(curveID))
+		})
+	}
+}
+
+// ProverFailed check that a solution does NOT solve a circuit; if expectedErr is given,
+// the error must match it (see errors.Is)
 //
 // solution must be map[string]interface{} or must implement frontend.Circuit
 // ( see frontend.ParseWitness )
-func (assert *Assert) ProverFailed(r1cs r1cs.R1CS, solution interface{}) {
+func (assert *Assert) ProverFailed(r1cs r1cs.R1CS, solution interface{}, expectedErr ...error) {
 	// setup
 	pk, err := DummySetup(r1cs)
 	assert.NoError(err)
 
 	_, err = Prove(r1cs, pk, assert.parseSolution(solution))
 	assert.Error(err, "proving with bad solution should output an error")
+	assert.expectedError(err, expectedErr)
 }
 
 // ProverSucceeded check that a solution solves a circuit
@@ -90,10 +190,35 @@ func (assert *Assert) ProverSucceeded(r1cs r1cs.R1CS, solution interface{}) {
 	assert.NoError(err, "proving with good solution should not output an error")
 
 	// ensure random sampling; calling prove twice with same input should produce different proof
+	// (the second time, the input is given as a typed witness)
+	w, err := witness.Full(r1cs, _solution)
+	assert.NoError(err)
 	{
-		proof2, err := Prove(r1cs, pk, _solution)
+		proof2, err := Prove(r1cs, pk, w)
 		assert.NoError(err, "proving with good solution should not output an error")
 		assert.False(reflect.DeepEqual(proof, proof2), "calling prove twice with same input should produce different proof")
+
+		// the verifier only accepts the public part of the witness
+		var buf bytes.Buffer
+		publicWitness, err := witness.Public(r1cs, _solution)
+		assert.NoError(err)
+		_, err = publicWitness.WriteTo(&buf)
+		assert.NoError(err)
+		publicWitness, err = witness.ReadPublic(&buf, r1cs.GetCurveID())
+		assert.NoError(err)
+		err = Verify(proof2, vk, publicWitness)
+		assert.NoError(err, "verifying proof with good public witness should not output an error")
+
+		if !w.IsPublic() {
+			err = Verify(proof2, vk, w)
+			assert.True(errors.Is(err, witness.ErrSecretInPublicWitness), "verifying proof with a full witness should output an error")
+
+			buf.Reset()
+			_, err = w.WriteTo(&buf)
+			assert.NoError(err)
+			_, err = witness.ReadPublic(&buf, r1cs.GetCurveID())
+			assert.Error(err, "reading a full witness as a public witness should output an error")
+		}
 	}
 
 	// verifier
@@ -109,6 +234,7 @@ func (assert *Assert) ProverSucceeded(r1cs r1cs.R1CS, solution interface{}) {
 	assert.serializationRawSucceeded(proof, NewProof(r1cs.GetCurveID()))
 	assert.serializationRawSucceeded(pk, NewProvingKey(r1cs.GetCurveID()))
 	assert.serializationRawSucceeded(vk, NewVerifyingKey(r1cs.GetCurveID()))
+	assert.serializationSucceeded(w, witness.New(r1cs.GetCurveID()))
 }
 
 func (assert *Assert) serializationSucceeded(from io.WriterTo, to io.ReaderFrom) {
@@ -141,12 +267,22 @@ func (assert *Assert) SolvingSucceeded(r1cs r1cs.R1CS, solution interface{}) {
 	assert.NoError(r1cs.IsSolved(assert.parseSolution(solution)))
 }
 
-// SolvingFailed Verifies that the R1CS is not solved with the given solution, without executing groth16 workflow
+// SolvingFailed Verifies that the R1CS is not solved with the given solution, without executing groth16 workflow;
+// if expectedErr is given, the error must match it (see errors.Is)
 //
 // solution must be map[string]interface{} or must implement frontend.Circuit
 // ( see frontend.ParseWitness )
-func (assert *Assert) SolvingFailed(r1cs r1cs.R1CS, solution interface{}) {
-	assert.Error(r1cs.IsSolved(assert.parseSolution(solution)))
+func (assert *Assert) SolvingFailed(r1cs r1cs.R1CS, solution interface{}, expectedErr ...error) {
+	err := r1cs.IsSolved(assert.parseSolution(solution))
+	assert.Error(err)
+	assert.expectedError(err, expectedErr)
+}
+
+// expectedError checks err matches expectedErr[0] (see errors.Is), if given
+func (assert *Assert) expectedError(err error, expectedErr []error) {
+	if len(expectedErr) != 0 {
+		assert.Truef(errors.Is(err, expectedErr[0]), "expected error %q, got %v", expectedErr[0], err)
+	}
 }
 
 func (assert *Assert) parseSolution(solution interface{}) map[string]interface{} {
@@ -154,3 +290,62 @@ func (assert *Assert) parseSolution(solution interface{}) map[string]interface{}
 	assert.NoError(err)
 	return _solution
 }
+
+// copyCircuit returns a copy of circuit which doesn't share its Variables (in slices, maps, pointers...)
+func copyCircuit(circuit frontend.Circuit) frontend.Circuit {
+	src := reflect.ValueOf(circuit)
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src)
+	return dst.Interface().(frontend.Circuit)
+}
+
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(v, src.Elem())
+		dst.Set(v)
+	case reflect.Struct:
+		// unexported fields are shallow copied
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(v, iter.Value())
+			dst.SetMapIndex(iter.Key(), v)
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fuzz checks circuits against native implementations on random assignments, with gopter
+//
+// it is a separate package so that importing backend/groth16 doesn't import gopter
+package fuzz
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// Option tunes the behavior of Check
+type Option func(config *config)
+
+type config struct {
+	derive     func(assignment map[string]interface{}) error
+	parameters *gopter.TestParameters
+}
+
+// WithDerivedInputs sets a function computing some inputs of the assignment from the generated values,
+// for example a hash from its pre-image. It is called again on each shrunk assignment, and an error it
+// returns fails the check (with the assignment it was given)
+func WithDerivedInputs(derive func(assignment map[string]interface{}) error) Option {
+	return func(config *config) {
+		config.derive = derive
+	}
+}
+
+// WithTestParameters sets the gopter parameters (by default, gopter.DefaultTestParameters())
+func WithTestParameters(parameters *gopter.TestParameters) Option {
+	return func(config *config) {
+		config.parameters = parameters
+	}
+}
+
+// Check checks that the R1CS accepts exactly the assignments the reference (a native implementation of the circuit)
+// accepts, on random assignments.
+//
+// gens generate the values of the assignment (map[string]interface{}, see frontend.ParseWitness) by name; names which
+// are not inputs of the circuit are ignored by the solver, and can be used to compute the inputs (see WithDerivedInputs).
+// On a mismatch, the generated values are shrunk (see gopter.Shrinker) and the smallest counterexample is reported
+func Check(t *testing.T, r1cs r1cs.R1CS, gens map[string]gopter.Gen, reference func(assignment map[string]interface{}) bool, opts ...Option) {
+	config := config{
+		parameters: gopter.DefaultTestParameters(),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// the values are generated (and shrunk) in the order of their names
+	names := make([]string, 0, len(gens))
+	for name := range gens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_gens := make([]gopter.Gen, len(names))
+	for i, name := range names {
+		_gens[i] = gens[name].WithLabel(name)
+	}
+
+	properties := gopter.NewProperties(config.parameters)
+	properties.Property("the R1CS accepts exactly the assignments the reference accepts", prop.ForAll(
+		func(values []interface{}) string {
+			assignment := make(map[string]interface{}, len(names))
+			for i, name := range names {
+				assignment[name] = values[i]
+			}
+			if config.derive != nil {
+				if err := config.derive(assignment); err != nil {
+					return fmt.Sprintf("can't derive the inputs of the assignment %v: %v", assignment, err)
+				}
+			}
+
+			expected := reference(assignment)
+			err := r1cs.IsSolved(assignment)
+			if expected && err != nil {
+				return fmt.Sprintf("the reference accepts the assignment %v, the solver fails with %v", assignment, err)
+			}
+			if !expected && err == nil {
+				return fmt.Sprintf("the reference rejects the assignment %v, the solver accepts it", assignment)
+			}
+			return ""
+		},
+		gopter.CombineGens(_gens...),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
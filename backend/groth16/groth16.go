@@ -16,6 +16,7 @@
 package groth16
 
 import (
+	"errors"
 	"io"
 
 	"github.com/consensys/gurvy"
@@ -28,12 +29,15 @@ import (
 	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/backend/witness"
 	groth16_bls377 "github.com/consensys/gnark/internal/backend/bls377/groth16"
 	groth16_bls381 "github.com/consensys/gnark/internal/backend/bls381/groth16"
 	groth16_bn256 "github.com/consensys/gnark/internal/backend/bn256/groth16"
 	groth16_bw761 "github.com/consensys/gnark/internal/backend/bw761/groth16"
 )
 
+var errWitnessCurveMismatch = errors.New("witness curve doesn't match")
+
 // Proof represents a Groth16 proof generated by groth16.Prove
 //
 // it's underlying implementation is curve specific (see gnark/internal/backend)
@@ -64,7 +68,14 @@ type VerifyingKey interface {
 }
 
 // Verify runs the groth16.Verify algorithm on provided proof with given solution
+//
+// solution must be a witness.Witness, a map[string]interface{} or must implement frontend.Circuit;
+// the secret values of a map or a circuit are ignored, but a witness.Witness must be public (see witness.Public),
+// or Verify returns witness.ErrSecretInPublicWitness
 func Verify(proof Proof, vk VerifyingKey, solution interface{}) error {
+	if w, ok := solution.(witness.Witness); ok {
+		return verifyWitness(proof, vk, w)
+	}
 	_solution, err := frontend.ParseWitness(solution)
 	if err != nil {
 		return err
@@ -86,19 +97,25 @@ func Verify(proof Proof, vk VerifyingKey, solution interface{}) error {
 // Prove generates the proof of knoweldge of a r1cs with solution.
 // if force flag is set, Prove ignores R1CS solving error (ie invalid solution) and executes
 // the FFTs and MultiExponentiations to compute an (invalid) Proof object
+//
+// solution must be a witness.Witness, a map[string]interface{} or must implement frontend.Circuit
 func Prove(r1cs r1cs.R1CS, pk ProvingKey, solution interface{}, force ...bool) (Proof, error) {
 
+	_force := false
+	if len(force) > 0 {
+		_force = force[0]
+	}
+
+	if w, ok := solution.(witness.Witness); ok {
+		return proveWitness(r1cs, pk, w, _force)
+	}
+
 	_solution, err := frontend.ParseWitness(solution)
 
 	if err != nil {
 		return nil, err
 	}
 
-	_force := false
-	if len(force) > 0 {
-		_force = force[0]
-	}
-
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls377.R1CS:
 		return groth16_bls377.Prove(_r1cs, pk.(*groth16_bls377.ProvingKey), _solution, _force)
@@ -113,6 +130,54 @@ func Prove(r1cs r1cs.R1CS, pk ProvingKey, solution interface{}, force ...bool) (
 	}
 }
 
+func proveWitness(r1cs r1cs.R1CS, pk ProvingKey, w witness.Witness, force bool) (Proof, error) {
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls377.R1CS:
+		if _w, ok := w.(*backend_bls377.Witness); ok {
+			return groth16_bls377.ProveWitness(_r1cs, pk.(*groth16_bls377.ProvingKey), _w, force)
+		}
+	case *backend_bls381.R1CS:
+		if _w, ok := w.(*backend_bls381.Witness); ok {
+			return groth16_bls381.ProveWitness(_r1cs, pk.(*groth16_bls381.ProvingKey), _w, force)
+		}
+	case *backend_bn256.R1CS:
+		if _w, ok := w.(*backend_bn256.Witness); ok {
+			return groth16_bn256.ProveWitness(_r1cs, pk.(*groth16_bn256.ProvingKey), _w, force)
+		}
+	case *backend_bw761.R1CS:
+		if _w, ok := w.(*backend_bw761.Witness); ok {
+			return groth16_bw761.ProveWitness(_r1cs, pk.(*groth16_bw761.ProvingKey), _w, force)
+		}
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	return nil, errWitnessCurveMismatch
+}
+
+func verifyWitness(proof Proof, vk VerifyingKey, w witness.Witness) error {
+	switch _proof := proof.(type) {
+	case *groth16_bls377.Proof:
+		if _w, ok := w.(*backend_bls377.Witness); ok {
+			return groth16_bls377.VerifyWitness(_proof, vk.(*groth16_bls377.VerifyingKey), _w)
+		}
+	case *groth16_bls381.Proof:
+		if _w, ok := w.(*backend_bls381.Witness); ok {
+			return groth16_bls381.VerifyWitness(_proof, vk.(*groth16_bls381.VerifyingKey), _w)
+		}
+	case *groth16_bn256.Proof:
+		if _w, ok := w.(*backend_bn256.Witness); ok {
+			return groth16_bn256.VerifyWitness(_proof, vk.(*groth16_bn256.VerifyingKey), _w)
+		}
+	case *groth16_bw761.Proof:
+		if _w, ok := w.(*backend_bw761.Witness); ok {
+			return groth16_bw761.VerifyWitness(_proof, vk.(*groth16_bw761.VerifyingKey), _w)
+		}
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	return errWitnessCurveMismatch
+}
+
 // Setup runs groth16.Setup with provided R1CS
 func Setup(r1cs r1cs.R1CS) (ProvingKey, VerifyingKey, error) {
 
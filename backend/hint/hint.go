@@ -0,0 +1,154 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hint allows to define computations outside of a circuit.
+//
+// A hint is a Go function which computes a wire value from other wire values when the R1CS is solved.
+// The value it returns is NOT constrained: the circuit developer must add the constraints ensuring
+// that the hint result is correct (for example, to compute a square root s of x, one would use a hint
+// then assert that s*s == x).
+//
+// Hint functions are not serialized with the R1CS; only their ID is. A function must be registered
+// (see Register) in the process solving the R1CS; frontend.ConstraintSystem.NewHint registers
+// the function it is given, and the hints defined in this package are registered by default.
+package hint
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/big"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// ID is a unique identifier for a hint function, used to retrieve it when solving a R1CS
+type ID uint32
+
+// Function computes result from inputs.
+//
+// inputs and result are reduced modulo the modulus of the scalar field of the curve
+// the R1CS is solved on (inputs are in regular form, not in Montgomery form).
+// A Function must not modify its inputs.
+type Function func(modulus *big.Int, inputs []*big.Int, result *big.Int) error
+
+// ErrNotRegistered is returned by the R1CS solver when it encounters a hint which
+// was not registered with Register
+var ErrNotRegistered = errors.New("hint function is not registered")
+
+var (
+	registry   = make(map[ID]Function)
+	registryMu sync.RWMutex
+)
+
+func init() {
+	Register(InvZero)
+	Register(IntDiv)
+	Register(IntMod)
+	Register(IthBit)
+	Register(Identity)
+}
+
+// UUID returns a unique ID for the hint function, derived from its fully qualified name.
+//
+// Two distinct functions must not share the same name, which means anonymous functions
+// (closures) should not be used as hints.
+func UUID(f Function) ID {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return ID(h.Sum32())
+}
+
+// Register adds f to the hint registry, and returns its ID.
+// It is safe to register a function several times.
+func Register(f Function) ID {
+	id := UUID(f)
+	registryMu.Lock()
+	registry[id] = f
+	registryMu.Unlock()
+	return id
+}
+
+// Lookup returns the hint function registered with id
+func Lookup(id ID) (Function, bool) {
+	registryMu.RLock()
+	f, ok := registry[id]
+	registryMu.RUnlock()
+	return f, ok
+}
+
+// InvZero computes result = 1 / inputs[0] if inputs[0] != 0, and result = 0 otherwise
+func InvZero(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 1 {
+		return errors.New("InvZero expects one input")
+	}
+	if inputs[0].Sign() == 0 {
+		result.SetUint64(0)
+		return nil
+	}
+	if result.ModInverse(inputs[0], modulus) == nil {
+		return errors.New("InvZero: input is not invertible")
+	}
+	return nil
+}
+
+// IntDiv computes result = inputs[0] / inputs[1], the quotient of the euclidean division
+// of the inputs seen as (non negative) integers
+func IntDiv(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 2 {
+		return errors.New("IntDiv expects two inputs")
+	}
+	if inputs[1].Sign() == 0 {
+		return errors.New("IntDiv: division by zero")
+	}
+	result.Div(inputs[0], inputs[1])
+	return nil
+}
+
+// IntMod computes result = inputs[0] mod inputs[1], the remainder of the euclidean division
+// of the inputs seen as (non negative) integers
+func IntMod(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 2 {
+		return errors.New("IntMod expects two inputs")
+	}
+	if inputs[1].Sign() == 0 {
+		return errors.New("IntMod: division by zero")
+	}
+	result.Mod(inputs[0], inputs[1])
+	return nil
+}
+
+// IthBit computes result = the inputs[1]-th bit of inputs[0]
+func IthBit(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 2 {
+		return errors.New("IthBit expects two inputs")
+	}
+	if !inputs[1].IsUint64() {
+		return errors.New("IthBit: invalid bit index")
+	}
+	result.SetUint64(uint64(inputs[0].Bit(int(inputs[1].Uint64()))))
+	return nil
+}
+
+// Identity computes result = inputs[0]
+//
+// it is used to compute the wires which were removed from the constraints (see r1cs.UntypedR1CS.Optimize)
+func Identity(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 1 {
+		return errors.New("Identity expects one input")
+	}
+	result.Set(inputs[0])
+	return nil
+}
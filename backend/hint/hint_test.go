@@ -0,0 +1,103 @@
+package hint
+
+import (
+	"math/big"
+	"testing"
+)
+
+func double(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	result.Lsh(inputs[0], 1)
+	return nil
+}
+
+func triple(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	result.Mul(inputs[0], big.NewInt(3))
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	if UUID(double) == UUID(triple) {
+		t.Fatal("distinct functions should have distinct IDs")
+	}
+	if UUID(double) != UUID(double) {
+		t.Fatal("UUID should be deterministic")
+	}
+	if _, ok := Lookup(UUID(triple)); ok {
+		t.Fatal("triple should not be registered")
+	}
+
+	id := Register(double)
+	f, ok := Lookup(id)
+	if !ok {
+		t.Fatal("double should be registered")
+	}
+
+	var result big.Int
+	if err := f(nil, []*big.Int{big.NewInt(21)}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Int64() != 42 {
+		t.Fatal("registered function doesn't match")
+	}
+}
+
+func TestInvZero(t *testing.T) {
+	modulus := big.NewInt(101)
+	var result big.Int
+
+	if err := InvZero(modulus, []*big.Int{big.NewInt(0)}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Sign() != 0 {
+		t.Fatal("InvZero(0) should be 0")
+	}
+
+	if err := InvZero(modulus, []*big.Int{big.NewInt(42)}, &result); err != nil {
+		t.Fatal(err)
+	}
+	result.Mul(&result, big.NewInt(42)).Mod(&result, modulus)
+	if result.Int64() != 1 {
+		t.Fatal("InvZero(42) should be the inverse of 42")
+	}
+
+	if _, ok := Lookup(UUID(InvZero)); !ok {
+		t.Fatal("InvZero should be registered by default")
+	}
+}
+
+func TestIntDivMod(t *testing.T) {
+	modulus := big.NewInt(101)
+	var q, r big.Int
+
+	a, b := big.NewInt(95), big.NewInt(7)
+	if err := IntDiv(modulus, []*big.Int{a, b}, &q); err != nil {
+		t.Fatal(err)
+	}
+	if err := IntMod(modulus, []*big.Int{a, b}, &r); err != nil {
+		t.Fatal(err)
+	}
+	if q.Int64() != 13 || r.Int64() != 4 {
+		t.Fatal("95 = 13*7 + 4, got", q.String(), r.String())
+	}
+
+	if err := IntDiv(modulus, []*big.Int{a, big.NewInt(0)}, &q); err == nil {
+		t.Fatal("IntDiv by 0 should fail")
+	}
+	if err := IntMod(modulus, []*big.Int{a, big.NewInt(0)}, &r); err == nil {
+		t.Fatal("IntMod by 0 should fail")
+	}
+}
+
+func TestIthBit(t *testing.T) {
+	modulus := big.NewInt(101)
+	var result big.Int
+
+	for i, expected := range []int64{0, 1, 1, 0, 1, 0, 1, 0} {
+		if err := IthBit(modulus, []*big.Int{big.NewInt(86), big.NewInt(int64(i))}, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Int64() != expected {
+			t.Fatal("bit", i, "of 86 should be", expected)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1cs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+	"github.com/consensys/gurvy"
+)
+
+// DumpFormat is the output format of Dump
+type DumpFormat uint8
+
+const (
+	// DumpText writes one line per hint and constraint
+	DumpText DumpFormat = iota
+
+	// DumpJSON writes a R1CSDump
+	DumpJSON
+)
+
+// R1CSDump is a human-readable description of a R1CS (see Dump)
+//
+// the inputs are named after the circuit, the internal wires are named #id (their ID in the R1CS),
+// and the ONE wire only shows as constants in the linear expressions
+type R1CSDump struct {
+	Curve           string             `json:"curve"`
+	NbInternalWires int                `json:"nbInternalWires"`
+	SecretWires     []string           `json:"secretWires"`
+	PublicWires     []string           `json:"publicWires"`
+	Hints           []DumpedHint       `json:"hints"`
+	Constraints     []DumpedConstraint `json:"constraints"`
+}
+
+// DumpedHint is a wire computed by a hint function, in a R1CSDump
+type DumpedHint struct {
+	Wire     string   `json:"wire"`
+	Function string   `json:"function"` // name of the hint function, or its ID if it is not registered
+	Inputs   []string `json:"inputs"`
+}
+
+func (h DumpedHint) String() string {
+	return fmt.Sprintf("%s = %s(%s)", h.Wire, h.Function, strings.Join(h.Inputs, ", "))
+}
+
+// DumpedConstraint is a constraint L * R = O, in a R1CSDump
+type DumpedConstraint struct {
+	ID        int    `json:"id"`
+	Assertion bool   `json:"assertion"` // false for a computational constraint, which the solver uses to compute a wire
+	L         string `json:"l"`
+	R         string `json:"r"`
+	O         string `json:"o"`
+	DebugInfo string `json:"debugInfo,omitempty"` // debug info of the assertion, with the names of the wires
+	Component string `json:"component,omitempty"` // path of the component of the constraint, if any
+}
+
+func (c DumpedConstraint) String() string {
+	var sb strings.Builder
+	kind := "computational"
+	if c.Assertion {
+		kind = "assertion"
+	}
+	fmt.Fprintf(&sb, "#%d %s: (%s) * (%s) = %s", c.ID, kind, c.L, c.R, c.O)
+	if c.DebugInfo != "" {
+		sb.WriteString("  // ")
+		sb.WriteString(c.DebugInfo)
+	}
+	if c.Component != "" {
+		fmt.Fprintf(&sb, "  [%s]", c.Component)
+	}
+	return sb.String()
+}
+
+// Dump writes the hints and the constraints of the R1CS (typed or untyped) to w, in the given format
+//
+// the coefficients of a typed R1CS are written in signed form: -1 rather than the modulus minus 1
+func Dump(r1cs R1CS, w io.Writer, format DumpFormat) error {
+	dump := NewDump(r1cs)
+	switch format {
+	case DumpJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(dump)
+	case DumpText:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "R1CS (%s): %d internal wires, secret inputs %v, public inputs %v\n", dump.Curve, dump.NbInternalWires, dump.SecretWires, dump.PublicWires)
+		for _, h := range dump.Hints {
+			fmt.Fprintf(&sb, "hint: %s\n", h)
+		}
+		for _, c := range dump.Constraints {
+			sb.WriteString(c.String())
+			sb.WriteString("\n")
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	default:
+		return fmt.Errorf("unknown dump format %d", format)
+	}
+}
+
+// NewDump returns a human-readable description of the R1CS (typed or untyped)
+func NewDump(r1cs R1CS) *R1CSDump {
+	untyped, modulus := toUntyped(r1cs)
+	d := dumper{
+		r1cs:       untyped,
+		modulus:    modulus,
+		nbInternal: int(untyped.NbWires - untyped.NbPublicWires - untyped.NbSecretWires),
+		oneWire:    int(untyped.NbWires - untyped.NbPublicWires),
+	}
+
+	dump := &R1CSDump{
+		Curve:           "untyped",
+		NbInternalWires: d.nbInternal,
+		SecretWires:     untyped.SecretWires,
+		PublicWires:     untyped.PublicWires,
+		Hints:           make([]DumpedHint, len(untyped.Hints)),
+		Constraints:     make([]DumpedConstraint, len(untyped.Constraints)),
+	}
+	if curveID := r1cs.GetCurveID(); curveID != gurvy.UNKNOWN {
+		dump.Curve = curveID.String()
+	}
+
+	for i, h := range untyped.Hints {
+		dump.Hints[i] = DumpedHint{
+			Wire:     d.wireName(h.WireID),
+			Function: hintName(h.ID),
+			Inputs:   make([]string, len(h.Inputs)),
+		}
+		for j, le := range h.Inputs {
+			dump.Hints[i].Inputs[j] = d.linearExpression(le)
+		}
+	}
+
+	nbCO := int(untyped.NbCOConstraints)
+	for i := range untyped.Constraints {
+		r := &untyped.Constraints[i]
+		c := DumpedConstraint{
+			ID:        i,
+			Assertion: i >= nbCO,
+			L:         d.linearExpression(r.L),
+			R:         d.linearExpression(r.R),
+			O:         d.linearExpression(r.O),
+		}
+		if c.Assertion && len(untyped.DebugInfo) != 0 {
+			c.DebugInfo = resolveLogEntry(untyped.DebugInfo[i-nbCO], d.wireName)
+		}
+		if id := r1c.FindComponent(untyped.Components, i); id != -1 {
+			c.Component = r1c.ComponentPath(untyped.Components, id)
+		}
+		dump.Constraints[i] = c
+	}
+
+	return dump
+}
+
+type dumper struct {
+	r1cs       *UntypedR1CS
+	modulus    *big.Int // nil if the R1CS is untyped
+	nbInternal int
+	oneWire    int
+}
+
+// wireName returns the name of the input, or #id for an internal wire
+func (d *dumper) wireName(wireID int) string {
+	switch {
+	case wireID >= d.oneWire:
+		return d.r1cs.PublicWires[wireID-d.oneWire]
+	case wireID >= d.nbInternal:
+		return d.r1cs.SecretWires[wireID-d.nbInternal]
+	default:
+		return fmt.Sprintf("#%d", wireID)
+	}
+}
+
+// linearExpression returns le as "3*x - y + 5" ("0" if le is empty)
+func (d *dumper) linearExpression(le r1c.LinearExpression) string {
+	if len(le) == 0 {
+		return "0"
+	}
+	var sb strings.Builder
+	for i, t := range le {
+		var c big.Int
+		c.Set(&d.r1cs.Coefficients[t.CoeffID()])
+		if d.modulus != nil {
+			// signed form, in (-modulus/2, modulus/2]
+			c.Mod(&c, d.modulus)
+			var half big.Int
+			if half.Rsh(d.modulus, 1); c.Cmp(&half) > 0 {
+				c.Sub(&c, d.modulus)
+			}
+		}
+
+		switch {
+		case i == 0 && c.Sign() < 0:
+			sb.WriteString("-")
+		case i != 0 && c.Sign() < 0:
+			sb.WriteString(" - ")
+		case i != 0:
+			sb.WriteString(" + ")
+		}
+		c.Abs(&c)
+
+		switch {
+		case t.VariableID() == d.oneWire:
+			sb.WriteString(c.String())
+		case c.IsInt64() && c.Int64() == 1:
+			sb.WriteString(d.wireName(t.VariableID()))
+		default:
+			sb.WriteString(c.String())
+			sb.WriteString("*")
+			sb.WriteString(d.wireName(t.VariableID()))
+		}
+	}
+	return sb.String()
+}
+
+// hintName returns the name of the hint function, without its package path, or its ID if it is not registered
+func hintName(id hint.ID) string {
+	f, ok := hint.Lookup(id)
+	if !ok {
+		return fmt.Sprintf("hint_%d", id)
+	}
+	return path.Base(runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name())
+}
+
+// resolveLogEntry formats the log entry, with the names of its wires
+func resolveLogEntry(entry backend.LogEntry, wireName func(wireID int) string) string {
+	names := make([]interface{}, len(entry.ToResolve))
+	for i, id := range entry.ToResolve {
+		names[i] = wireName(id)
+	}
+	return fmt.Sprintf(entry.Format, names...)
+}
@@ -0,0 +1,77 @@
+package r1cs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type dumpCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *dumpCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	x3 := cs.Mul(c.X, c.X, c.X)
+	cs.AssertIsEqual(c.Y, cs.Sub(cs.Add(x3, cs.Mul(c.X, 3), 5), c.X))
+	cs.AssertIsBoolean(cs.NewHint(hint.IthBit, c.X, 1))
+	return nil
+}
+
+func TestDump(t *testing.T) {
+	for _, curveID := range []gurvy.ID{gurvy.UNKNOWN, gurvy.BN256} {
+		var circuit dumpCircuit
+		res, err := frontend.Compile(curveID, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := r1cs.Dump(res, &buf, r1cs.DumpText); err != nil {
+			t.Fatal(err)
+		}
+		text := buf.String()
+		for _, expected := range []string{
+			"public inputs [ONE_WIRE Y]",
+			"hint: #2 = hint.IthBit(X, 1)",
+			"#0 computational: (X) * (X) = #0",
+			"#1 computational: (#0) * (X) = #1",
+			"#2 assertion: (Y) * (1) = ",
+			"#3 assertion: (#2) * (",
+		} {
+			if !strings.Contains(text, expected) {
+				t.Fatalf("expected %q in the dump, got\n%s", expected, text)
+			}
+		}
+		// the coefficients of a typed R1CS are in signed form
+		if !strings.Contains(text, "-#2 + 1") && !strings.Contains(text, "1 - #2") {
+			t.Fatalf("expected 1 - #2 in the dump, got\n%s", text)
+		}
+
+		buf.Reset()
+		if err := r1cs.Dump(res, &buf, r1cs.DumpJSON); err != nil {
+			t.Fatal(err)
+		}
+		var dump r1cs.R1CSDump
+		if err := json.Unmarshal(buf.Bytes(), &dump); err != nil {
+			t.Fatal(err)
+		}
+		if len(dump.Hints) != 1 || len(dump.Constraints) != int(res.GetNbConstraints()) {
+			t.Fatal("unexpected dump", dump)
+		}
+		for i, c := range dump.Constraints {
+			if c.ID != i || c.Assertion != (i >= 2) {
+				t.Fatal("unexpected constraint", c)
+			}
+		}
+		if !strings.Contains(dump.Constraints[2].DebugInfo, "Y") || !strings.Contains(dump.Constraints[2].DebugInfo, "#1") {
+			t.Fatal("expected the debug info with the wire names, got", dump.Constraints[2].DebugInfo)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1cs
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+)
+
+// LintKind is the kind of a LintIssue
+type LintKind uint8
+
+const (
+	// UnusedWire is a wire which appears in no constraint, its value is not constrained at all
+	UnusedWire LintKind = iota
+
+	// UnassertedInput is an input which influences no assertion
+	UnassertedInput
+
+	// UnassertedWire is a wire computed by the solver (by a computational constraint or a hint)
+	// which influences no assertion
+	UnassertedWire
+
+	// UnassertedBoolean is a bit (of a binary decomposition, or computed with hint.IthBit)
+	// which is not asserted to be boolean (see frontend.ConstraintSystem.AssertIsBoolean)
+	UnassertedBoolean
+)
+
+func (kind LintKind) String() string {
+	switch kind {
+	case UnusedWire:
+		return "unused wire"
+	case UnassertedInput:
+		return "input influencing no assertion"
+	case UnassertedWire:
+		return "computed wire influencing no assertion"
+	case UnassertedBoolean:
+		return "bit not asserted to be boolean"
+	default:
+		return "unknown issue"
+	}
+}
+
+// LintIssue is a wire which may be under-constrained (see Lint)
+type LintIssue struct {
+	Kind         LintKind
+	WireID       int
+	Wire         string   // name of the input, "internal wire #id" for an internal wire
+	ConstraintID int      // constraint computing the wire (or else the first one it appears in), -1 if none
+	DebugInfo    string   // debug info of the constraint if it is an assertion, with the names of the wires
+	Stack        []string // call stack of the constraint, if recorded (see frontend.WithCallStacks)
+	Component    string   // path of the component of the constraint, if any
+}
+
+func (issue LintIssue) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", issue.Kind, issue.Wire)
+	if issue.ConstraintID != -1 {
+		fmt.Fprintf(&sb, " (constraint #%d)", issue.ConstraintID)
+	}
+	if issue.DebugInfo != "" {
+		sb.WriteString(": ")
+		sb.WriteString(issue.DebugInfo)
+	}
+	if issue.Component != "" {
+		sb.WriteString("\nin component ")
+		sb.WriteString(issue.Component)
+	}
+	for i := 0; i < len(issue.Stack); i++ {
+		sb.WriteString("\n")
+		sb.WriteString(issue.Stack[i])
+	}
+	return sb.String()
+}
+
+// Lint looks for the wires of the R1CS which may be under-constrained:
+//
+// 1. wires which appear in no constraint (see UnusedWire)
+//
+// 2. inputs which influence no assertion (see UnassertedInput)
+//
+// 3. wires computed by the solver which influence no assertion (see UnassertedWire)
+//
+// 4. bits which are not asserted to be boolean (see UnassertedBoolean)
+//
+// a wire influences an assertion if it appears in it, or if a wire computed from it (by a computational
+// constraint or a hint) does. The issues are sorted by wire ID; the wires removed by the optimizations
+// (see UntypedR1CS.Optimize) are not reported
+func Lint(r1cs R1CS) []LintIssue {
+	untyped, modulus := toUntyped(r1cs)
+	l := linter{
+		r1cs:       untyped,
+		modulus:    modulus,
+		nbInternal: int(untyped.NbWires - untyped.NbPublicWires - untyped.NbSecretWires),
+		oneWire:    int(untyped.NbWires - untyped.NbPublicWires),
+	}
+	return l.run()
+}
+
+type linter struct {
+	r1cs       *UntypedR1CS
+	modulus    *big.Int // nil if the R1CS is untyped
+	nbInternal int
+	oneWire    int
+
+	firstConstraint []int   // first constraint the wire appears in, -1 if none
+	computedBy      []int   // computational constraint computing the wire, -1 if none
+	hints           []int   // hint computing the wire, -1 if none
+	deps            [][]int // wires the wire is computed from
+	influences      []bool  // the wire influences an assertion
+	isBit           []bool  // the wire is a bit of a binary decomposition or computed with hint.IthBit
+	isBoolean       []bool  // the wire is asserted to be boolean
+}
+
+func (l *linter) run() []LintIssue {
+	r1cs := l.r1cs
+	nbWires := int(r1cs.NbWires)
+	nbCO := int(r1cs.NbCOConstraints)
+
+	l.firstConstraint = make([]int, nbWires)
+	l.computedBy = make([]int, nbWires)
+	l.hints = make([]int, nbWires)
+	l.deps = make([][]int, nbWires)
+	l.influences = make([]bool, nbWires)
+	l.isBit = make([]bool, nbWires)
+	l.isBoolean = make([]bool, nbWires)
+	for i := 0; i < nbWires; i++ {
+		l.firstConstraint[i] = -1
+		l.computedBy[i] = -1
+		l.hints[i] = -1
+	}
+
+	for i := len(r1cs.Constraints) - 1; i >= 0; i-- {
+		r := &r1cs.Constraints[i]
+		for _, linExp := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+			for _, t := range linExp {
+				l.firstConstraint[t.VariableID()] = i
+			}
+		}
+	}
+
+	// the inputs are known, and the hints are solved on demand
+	instantiated := make([]bool, nbWires)
+	for i := l.nbInternal; i < nbWires; i++ {
+		instantiated[i] = true
+	}
+	ithBit, identity := hint.UUID(hint.IthBit), hint.UUID(hint.Identity)
+	for i, h := range r1cs.Hints {
+		l.hints[h.WireID] = i
+		instantiated[h.WireID] = true
+		l.isBit[h.WireID] = h.ID == ithBit
+		for _, linExp := range h.Inputs {
+			l.deps[h.WireID] = appendWires(l.deps[h.WireID], linExp)
+		}
+	}
+
+	// the computational constraints are solved in order, as in R1CS.Solve
+	for i := 0; i < nbCO; i++ {
+		r := &r1cs.Constraints[i]
+		var outputs, inputs []int
+		for _, linExp := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+			for _, t := range linExp {
+				wireID := t.VariableID()
+				if instantiated[wireID] {
+					inputs = append(inputs, wireID)
+					continue
+				}
+				instantiated[wireID] = true
+				outputs = append(outputs, wireID)
+			}
+		}
+		for _, wireID := range outputs {
+			l.computedBy[wireID] = i
+			l.deps[wireID] = inputs
+			l.isBit[wireID] = r.Solver == r1c.BinaryDec
+		}
+	}
+
+	// the wires of the assertions, and transitively the wires they are computed from, influence an assertion
+	var queue []int
+	for i := nbCO; i < len(r1cs.Constraints); i++ {
+		r := &r1cs.Constraints[i]
+		for _, wireID := range appendWires(appendWires(appendWires(nil, r.L), r.R), r.O) {
+			if !l.influences[wireID] {
+				l.influences[wireID] = true
+				queue = append(queue, wireID)
+			}
+		}
+		if wireID, ok := l.booleanAssertion(r); ok {
+			l.isBoolean[wireID] = true
+		}
+	}
+	for len(queue) != 0 {
+		wireID := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, dep := range l.deps[wireID] {
+			if !l.influences[dep] {
+				l.influences[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	var issues []LintIssue
+	for wireID := 0; wireID < nbWires; wireID++ {
+		if wireID == l.oneWire || (l.hints[wireID] != -1 && r1cs.Hints[l.hints[wireID]].ID == identity) {
+			continue
+		}
+		constraintID := l.computedBy[wireID]
+		if constraintID == -1 {
+			constraintID = l.firstConstraint[wireID]
+		}
+
+		if l.firstConstraint[wireID] == -1 {
+			issues = append(issues, l.issue(UnusedWire, wireID, constraintID))
+			continue
+		}
+		if wireID >= l.nbInternal && !l.influences[wireID] {
+			issues = append(issues, l.issue(UnassertedInput, wireID, constraintID))
+		}
+		if wireID < l.nbInternal && (l.computedBy[wireID] != -1 || l.hints[wireID] != -1) && !l.influences[wireID] {
+			issues = append(issues, l.issue(UnassertedWire, wireID, constraintID))
+		}
+		if l.isBit[wireID] && !l.isBoolean[wireID] {
+			issues = append(issues, l.issue(UnassertedBoolean, wireID, constraintID))
+		}
+	}
+
+	return issues
+}
+
+// booleanAssertion returns the wire v if r is v * (1 - v) == 0 or v * v == v, up to the coefficients
+func (l *linter) booleanAssertion(r *r1c.R1C) (int, bool) {
+	coeff := func(t r1c.Term) *big.Int {
+		return &l.r1cs.Coefficients[t.CoeffID()]
+	}
+
+	// v * v == v
+	if len(r.L) == 1 && len(r.R) == 1 && len(r.O) == 1 {
+		v := r.L[0].VariableID()
+		if r.R[0].VariableID() != v || r.O[0].VariableID() != v {
+			return 0, false
+		}
+		var ab big.Int
+		ab.Mul(coeff(r.L[0]), coeff(r.R[0]))
+		return v, l.equal(&ab, coeff(r.O[0]))
+	}
+
+	// v * (1 - v) == 0
+	for _, t := range r.O {
+		if !l.equal(coeff(t), bZero) {
+			return 0, false
+		}
+	}
+	for _, lr := range [2][2]r1c.LinearExpression{{r.L, r.R}, {r.R, r.L}} {
+		v, oneMinusV := lr[0], lr[1]
+		if len(v) != 1 || len(oneMinusV) != 2 {
+			continue
+		}
+		wireID := v[0].VariableID()
+		for i := 0; i < 2; i++ {
+			one, minusV := oneMinusV[i], oneMinusV[1-i]
+			if one.VariableID() != l.oneWire || minusV.VariableID() != wireID || l.equal(coeff(one), bZero) {
+				continue
+			}
+			var sum big.Int
+			sum.Add(coeff(one), coeff(minusV))
+			if l.equal(&sum, bZero) {
+				return wireID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+var bZero = big.NewInt(0)
+
+// equal returns a == b, modulo the field modulus if it is known
+func (l *linter) equal(a, b *big.Int) bool {
+	if l.modulus == nil {
+		return a.Cmp(b) == 0
+	}
+	var diff big.Int
+	diff.Sub(a, b).Mod(&diff, l.modulus)
+	return diff.Sign() == 0
+}
+
+func (l *linter) issue(kind LintKind, wireID, constraintID int) LintIssue {
+	r1cs := l.r1cs
+	issue := LintIssue{
+		Kind:         kind,
+		WireID:       wireID,
+		Wire:         l.wireName(wireID),
+		ConstraintID: constraintID,
+	}
+	if constraintID == -1 {
+		return issue
+	}
+
+	nbCO := int(r1cs.NbCOConstraints)
+	if constraintID >= nbCO && len(r1cs.DebugInfo) != 0 {
+		issue.DebugInfo = resolveLogEntry(r1cs.DebugInfo[constraintID-nbCO], l.wireName)
+	}
+	if len(r1cs.CallStackIDs) != 0 && r1cs.CallStackIDs[constraintID] != -1 {
+		issue.Stack = r1cs.CallStacks[r1cs.CallStackIDs[constraintID]]
+	}
+	if id := r1c.FindComponent(r1cs.Components, constraintID); id != -1 {
+		issue.Component = r1c.ComponentPath(r1cs.Components, id)
+	}
+	return issue
+}
+
+// wireName returns the name of the input, or "internal wire #id"
+func (l *linter) wireName(wireID int) string {
+	switch {
+	case wireID >= l.oneWire:
+		return l.r1cs.PublicWires[wireID-l.oneWire]
+	case wireID >= l.nbInternal:
+		return l.r1cs.SecretWires[wireID-l.nbInternal]
+	default:
+		return fmt.Sprintf("internal wire #%d", wireID)
+	}
+}
+
+// appendWires appends the wires of linExp to wires
+func appendWires(wires []int, linExp r1c.LinearExpression) []int {
+	for _, t := range linExp {
+		wires = append(wires, t.VariableID())
+	}
+	return wires
+}
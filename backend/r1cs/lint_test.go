@@ -0,0 +1,86 @@
+package r1cs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type lintCircuit struct {
+	X, Y     frontend.Variable
+	Dangling frontend.Variable
+	Unused   frontend.Variable
+	Z        frontend.Variable `gnark:",public"`
+}
+
+func (circuit *lintCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	bits := cs.ToBinary(circuit.X, 4)
+	cs.AssertIsEqual(cs.Mul(circuit.X, circuit.Y), circuit.Z)
+
+	// the product is never asserted
+	cs.Mul(circuit.Dangling, circuit.X)
+
+	// b is not asserted to be boolean
+	b := cs.NewHint(hint.IthBit, circuit.Y, 0)
+	cs.AssertIsEqual(cs.Mul(b, circuit.Y), cs.Mul(bits[0], circuit.Z))
+
+	return nil
+}
+
+type assertedCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *assertedCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	cs.ToBinary(circuit.X, 2)
+	cs.AssertIsEqual(circuit.X, 1)
+	return nil
+}
+
+func TestLint(t *testing.T) {
+	for _, curveID := range []gurvy.ID{gurvy.UNKNOWN, gurvy.BN256} {
+		var circuit lintCircuit
+		res, err := frontend.Compile(curveID, &circuit, frontend.WithCallStacks())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		issues := r1cs.Lint(res)
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+
+		expected := []struct {
+			kind r1cs.LintKind
+			wire string
+			line string
+		}{
+			{r1cs.UnassertedWire, "internal wire #", "lint_test.go:25"},
+			{r1cs.UnassertedBoolean, "internal wire #", "lint_test.go:29"},
+			{r1cs.UnassertedInput, "Dangling", "lint_test.go:25"},
+			{r1cs.UnusedWire, "Unused", ""},
+		}
+		if len(issues) != len(expected) {
+			t.Fatal("expected", len(expected), "issues, got", len(issues))
+		}
+		for i := range expected {
+			if issues[i].Kind != expected[i].kind || !strings.HasPrefix(issues[i].Wire, expected[i].wire) || !strings.Contains(issues[i].String(), expected[i].line) {
+				t.Fatal("unexpected issue", issues[i])
+			}
+		}
+	}
+
+	// no issue in a circuit with all its wires asserted
+	var circuit assertedCircuit
+	res, err := frontend.Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := r1cs.Lint(res); len(issues) != 0 {
+		t.Fatal("unexpected issues", issues)
+	}
+}
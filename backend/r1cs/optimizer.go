@@ -0,0 +1,463 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1cs
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+	"github.com/consensys/gurvy"
+	frbls377 "github.com/consensys/gurvy/bls377/fr"
+	frbls381 "github.com/consensys/gurvy/bls381/fr"
+	frbn256 "github.com/consensys/gurvy/bn256/fr"
+	frbw761 "github.com/consensys/gurvy/bw761/fr"
+)
+
+// OptimizationStats reports the number of constraints before and after Optimize
+type OptimizationStats struct {
+	NbConstraintsBefore, NbConstraintsAfter     uint64 // total number of constraints
+	NbCOConstraintsBefore, NbCOConstraintsAfter uint64 // number of computational constraints
+}
+
+// Optimize reduces the number of constraints of the R1CS, for the scalar field of curveID:
+//
+// • a computational constraint which is linear (L or R is constant) is removed, and the wire it computes
+// is substituted by the equivalent linear expression in the other constraints. This removes all the
+// allocations L*1 = O of a linear expression, so the allocations of identical expressions are not kept twice
+// either, and the products using them are merged (see below)
+//
+// • a computational constraint which computes the same product as a previous one is removed,
+// and the wire it computes is substituted by the wire computed by the previous one
+//
+// • an assertion which always holds (for example, on constants only), or which is a duplicate, is removed
+//
+// linear expressions are reduced (one term per wire, no zero coefficient) along the way.
+// The wires are left in place: a removed wire is computed by the solver with the hint.Identity
+// hint, so that it can still be printed or referenced in debug info.
+//
+// The new coefficients may not fit in the terms: the R1CS must then be discarded, which the caller
+// checks with r1c.CheckCapacity (as frontend.Compile does).
+func (r1cs *UntypedR1CS) Optimize(curveID gurvy.ID) OptimizationStats {
+	stats := OptimizationStats{
+		NbConstraintsBefore:   r1cs.NbConstraints,
+		NbCOConstraintsBefore: r1cs.NbCOConstraints,
+	}
+
+	o := newOptimizer(r1cs, FieldModulus(curveID))
+	o.run()
+
+	stats.NbConstraintsAfter = r1cs.NbConstraints
+	stats.NbCOConstraintsAfter = r1cs.NbCOConstraints
+	return stats
+}
+
+// FieldModulus returns the modulus of the scalar field of the curve, in which the R1CS
+// coefficients and wire values live
+func FieldModulus(curveID gurvy.ID) *big.Int {
+	switch curveID {
+	case gurvy.BN256:
+		return frbn256.Modulus()
+	case gurvy.BLS377:
+		return frbls377.Modulus()
+	case gurvy.BLS381:
+		return frbls381.Modulus()
+	case gurvy.BW761:
+		return frbw761.Modulus()
+	default:
+		panic("not implemented")
+	}
+}
+
+// expression is a reduced linear expression: wireID -> coefficient (mod modulus, non zero)
+type expression map[int]*big.Int
+
+type optimizer struct {
+	r1cs    *UntypedR1CS
+	modulus *big.Int
+
+	oneWire                  int // ID of the ONE wire
+	firstSecret, firstPublic int // wire IDs are [internal | secret | public]
+	coeffIDs                 map[string]int
+	computed                 []bool             // wires known before the current constraint is solved
+	substitutions            map[int]expression // removed wires -> equivalent linear expression
+	products                 map[string]int     // computed products -> wire (see mergeProduct)
+	assertions               map[string]struct{}
+	kept                     []bool // constraints kept, indexed as r1cs.Constraints
+	constraints              []r1c.R1C
+	nbCOConstraints          int
+	debugInfo                []backend.LogEntry
+	callStackIDs             []int
+}
+
+func newOptimizer(r1cs *UntypedR1CS, modulus *big.Int) *optimizer {
+	o := &optimizer{
+		r1cs:          r1cs,
+		modulus:       modulus,
+		firstSecret:   int(r1cs.NbWires - r1cs.NbPublicWires - r1cs.NbSecretWires),
+		firstPublic:   int(r1cs.NbWires - r1cs.NbPublicWires),
+		coeffIDs:      make(map[string]int),
+		computed:      make([]bool, r1cs.NbWires),
+		substitutions: make(map[int]expression),
+		products:      make(map[string]int),
+		assertions:    make(map[string]struct{}),
+		kept:          make([]bool, len(r1cs.Constraints)),
+	}
+	o.oneWire = o.firstPublic
+
+	for i := 0; i < len(r1cs.Coefficients); i++ {
+		var c big.Int
+		c.Mod(&r1cs.Coefficients[i], modulus)
+		if _, ok := o.coeffIDs[c.Text(16)]; !ok {
+			o.coeffIDs[c.Text(16)] = i
+		}
+	}
+
+	// the inputs and the wires computed by hints are known before solving the constraints
+	for i := o.firstSecret; i < len(o.computed); i++ {
+		o.computed[i] = true
+	}
+	for _, h := range r1cs.Hints {
+		o.computed[h.WireID] = true
+	}
+
+	return o
+}
+
+func (o *optimizer) run() {
+	r1cs := o.r1cs
+
+	for i := 0; i < int(r1cs.NbCOConstraints); i++ {
+		o.optimizeConstraint(i)
+	}
+	o.nbCOConstraints = len(o.constraints)
+
+	for i := int(r1cs.NbCOConstraints); i < len(r1cs.Constraints); i++ {
+		o.optimizeAssertion(i)
+	}
+
+	// the removed wires are computed by the solver from their substitution
+	wires := make([]int, 0, len(o.substitutions))
+	for wireID := range o.substitutions {
+		wires = append(wires, wireID)
+	}
+	sort.Ints(wires)
+	for _, wireID := range wires {
+		r1cs.Hints = append(r1cs.Hints, r1c.Hint{
+			ID:     hint.UUID(hint.Identity),
+			WireID: wireID,
+			Inputs: []r1c.LinearExpression{o.linearExpression(o.substitutions[wireID])},
+		})
+	}
+
+	// constraint ranges [start, end) of the components are shifted by the constraints removed before them
+	newIndex := make([]int, len(o.kept)+1)
+	for i := 0; i < len(o.kept); i++ {
+		newIndex[i+1] = newIndex[i]
+		if o.kept[i] {
+			newIndex[i+1]++
+		}
+	}
+	for i := 0; i < len(r1cs.Components); i++ {
+		c := &r1cs.Components[i]
+		c.Constraints[0], c.Constraints[1] = newIndex[c.Constraints[0]], newIndex[c.Constraints[1]]
+		c.Assertions[0], c.Assertions[1] = newIndex[c.Assertions[0]], newIndex[c.Assertions[1]]
+	}
+
+	r1cs.Constraints = o.constraints
+	r1cs.DebugInfo = o.debugInfo
+	r1cs.CallStackIDs = o.callStackIDs
+	r1cs.NbCOConstraints = uint64(o.nbCOConstraints)
+	r1cs.NbConstraints = uint64(len(o.constraints))
+}
+
+// optimizeConstraint removes or rewrites the i-th computational constraint
+func (o *optimizer) optimizeConstraint(i int) {
+	r := o.r1cs.Constraints[i]
+
+	if r.Solver == r1c.BinaryDec {
+		// the solver identifies the bits in L by their coefficients, only O can be rewritten
+		o.keep(i, r1c.R1C{L: r.L, R: r.R, O: o.linearExpression(o.expression(r.O)), Solver: r.Solver})
+		for _, t := range r.L {
+			o.computed[t.VariableID()] = true
+		}
+		return
+	}
+
+	// wire computed by the constraint
+	wireID := -1
+	for _, le := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+		for _, t := range le {
+			if !o.computed[t.VariableID()] {
+				wireID = t.VariableID()
+			}
+		}
+	}
+
+	l, rr, out := o.expression(r.L), o.expression(r.R), o.expression(r.O)
+
+	if wireID == -1 {
+		// nothing to compute, the constraint is a check on known wires
+		o.keep(i, r1c.R1C{L: o.linearExpression(l), R: o.linearExpression(rr), O: o.linearExpression(out), Solver: r.Solver})
+		return
+	}
+	o.computed[wireID] = true
+
+	_, inL := l[wireID]
+	_, inR := rr[wireID]
+	_, inO := out[wireID]
+	if !inL && !inR && !inO {
+		// the wire vanished when the expressions were reduced, leave the constraint as it is
+		o.keep(i, r)
+		return
+	}
+
+	if o.substituteLinear(wireID, l, rr, out) || o.mergeProduct(wireID, l, rr, out) {
+		// the constraint is removed
+		return
+	}
+
+	o.keep(i, r1c.R1C{L: o.linearExpression(l), R: o.linearExpression(rr), O: o.linearExpression(out), Solver: r.Solver})
+}
+
+// substituteLinear records a substitution for wireID if l*r = o is linear
+func (o *optimizer) substituteLinear(wireID int, l, r, out expression) bool {
+	relation, ok := o.linearRelation(l, r, out)
+	if !ok {
+		return false
+	}
+
+	a, ok := relation[wireID]
+	if !ok {
+		return false
+	}
+	delete(relation, wireID)
+
+	// wire = -relation / a
+	var inv big.Int
+	inv.ModInverse(a, o.modulus)
+	inv.Neg(&inv)
+	o.substitutions[wireID] = o.scale(relation, o.reduce(&inv))
+	return true
+}
+
+// linearRelation returns l*r - o if l or r is constant
+func (o *optimizer) linearRelation(l, r, out expression) (expression, bool) {
+	cl, lIsConstant := o.constant(l)
+	cr, rIsConstant := o.constant(r)
+
+	switch {
+	case lIsConstant && rIsConstant:
+		var c big.Int
+		c.Mul(cl, cr)
+		return o.add(expression{o.oneWire: o.reduce(&c)}, out, bMinusOne), true
+	case lIsConstant:
+		return o.add(o.scale(r, cl), out, bMinusOne), true
+	case rIsConstant:
+		return o.add(o.scale(l, cr), out, bMinusOne), true
+	default:
+		return nil, false
+	}
+}
+
+// mergeProduct records a substitution for wireID if out = k*wireID and the same product l*r
+// was computed by a previous constraint
+func (o *optimizer) mergeProduct(wireID int, l, r, out expression) bool {
+	if len(out) != 1 {
+		return false
+	}
+	if _, ok := l[wireID]; ok {
+		return false
+	}
+	if _, ok := r[wireID]; ok {
+		return false
+	}
+
+	kl, kr := o.key(l), o.key(r)
+	if kr < kl {
+		kl, kr = kr, kl
+	}
+	key := out[wireID].Text(16) + "|" + kl + "|" + kr
+
+	if previous, ok := o.products[key]; ok {
+		o.substitutions[wireID] = expression{previous: big.NewInt(1)}
+		return true
+	}
+	o.products[key] = wireID
+	return false
+}
+
+// optimizeAssertion removes or rewrites the i-th constraint, which is an assertion
+func (o *optimizer) optimizeAssertion(i int) {
+	r := o.r1cs.Constraints[i]
+	l, rr, out := o.expression(r.L), o.expression(r.R), o.expression(r.O)
+
+	// assertion which always holds (l*r - o is linear and reduces to 0)
+	if relation, ok := o.linearRelation(l, rr, out); ok && len(relation) == 0 {
+		return
+	}
+
+	// duplicate assertion
+	kl, kr := o.key(l), o.key(rr)
+	if kr < kl {
+		kl, kr = kr, kl
+	}
+	key := kl + "|" + kr + "|" + o.key(out)
+	if _, ok := o.assertions[key]; ok {
+		return
+	}
+	o.assertions[key] = struct{}{}
+
+	o.keep(i, r1c.R1C{L: o.linearExpression(l), R: o.linearExpression(rr), O: o.linearExpression(out), Solver: r.Solver})
+	if len(o.r1cs.DebugInfo) != 0 {
+		o.debugInfo = append(o.debugInfo, o.r1cs.DebugInfo[i-int(o.r1cs.NbCOConstraints)])
+	}
+}
+
+func (o *optimizer) keep(i int, r r1c.R1C) {
+	o.kept[i] = true
+	o.constraints = append(o.constraints, r)
+	if len(o.r1cs.CallStackIDs) != 0 {
+		o.callStackIDs = append(o.callStackIDs, o.r1cs.CallStackIDs[i])
+	}
+}
+
+var bMinusOne = big.NewInt(-1)
+
+// reduce returns c mod modulus
+func (o *optimizer) reduce(c *big.Int) *big.Int {
+	return new(big.Int).Mod(c, o.modulus)
+}
+
+// expression returns the reduced linear expression, after the substitution of the removed wires
+func (o *optimizer) expression(le r1c.LinearExpression) expression {
+	res := make(expression, len(le))
+	for _, t := range le {
+		c := o.reduce(&o.r1cs.Coefficients[t.CoeffID()])
+		if s, ok := o.substitutions[t.VariableID()]; ok {
+			res = o.add(res, s, c)
+			continue
+		}
+		res = o.add(res, expression{t.VariableID(): c}, bOne)
+	}
+	return res
+}
+
+var bOne = big.NewInt(1)
+
+// add returns a + k*b, a is modified
+func (o *optimizer) add(a, b expression, k *big.Int) expression {
+	for wireID, c := range b {
+		var t big.Int
+		t.Mul(c, k)
+		if ca, ok := a[wireID]; ok {
+			t.Add(&t, ca)
+		}
+		t.Mod(&t, o.modulus)
+		if t.Sign() == 0 {
+			delete(a, wireID)
+		} else {
+			a[wireID] = &t
+		}
+	}
+	return a
+}
+
+// scale returns k*a in a new expression
+func (o *optimizer) scale(a expression, k *big.Int) expression {
+	return o.add(make(expression, len(a)), a, k)
+}
+
+// constant returns the value of a if it only depends on the ONE wire
+func (o *optimizer) constant(a expression) (*big.Int, bool) {
+	switch len(a) {
+	case 0:
+		return new(big.Int), true
+	case 1:
+		if c, ok := a[o.oneWire]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// key returns a string identifying the expression
+func (o *optimizer) key(a expression) string {
+	wires := o.sortedWires(a)
+	var sb strings.Builder
+	for _, wireID := range wires {
+		sb.WriteString(big.NewInt(int64(wireID)).Text(16))
+		sb.WriteByte(':')
+		sb.WriteString(a[wireID].Text(16))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+func (o *optimizer) sortedWires(a expression) []int {
+	wires := make([]int, 0, len(a))
+	for wireID := range a {
+		wires = append(wires, wireID)
+	}
+	sort.Ints(wires)
+	return wires
+}
+
+// linearExpression packs the expression in terms, adding the new coefficients to the R1CS
+func (o *optimizer) linearExpression(a expression) r1c.LinearExpression {
+	wires := o.sortedWires(a)
+	res := make(r1c.LinearExpression, len(wires))
+	for i, wireID := range wires {
+		c := a[wireID]
+
+		coeffID, ok := o.coeffIDs[c.Text(16)]
+		if !ok {
+			coeffID = len(o.r1cs.Coefficients)
+			o.r1cs.Coefficients = append(o.r1cs.Coefficients, *new(big.Int).Set(c))
+			o.coeffIDs[c.Text(16)] = coeffID
+		}
+
+		visibility := backend.Internal
+		if wireID >= o.firstPublic {
+			visibility = backend.Public
+		} else if wireID >= o.firstSecret {
+			visibility = backend.Secret
+		}
+
+		// the wire IDs are unchanged, a wire which doesn't fit in a term was already in wide terms
+		switch {
+		case r1c.CheckCapacity(wireID+1, coeffID+1, true) != nil:
+			res[i] = r1c.Pack(0, 0, visibility) // the coefficients overflow, see Optimize
+		case wireID >= r1c.MaxWires:
+			res[i] = r1c.PackWide(wireID, coeffID, visibility)
+		default:
+			res[i] = r1c.Pack(wireID, coeffID, visibility)
+		}
+		var minusOne big.Int
+		minusOne.Sub(o.modulus, bOne)
+		switch {
+		case c.Cmp(bOne) == 0:
+			res[i].SetCoeffValue(1)
+		case c.Cmp(big.NewInt(2)) == 0:
+			res[i].SetCoeffValue(2)
+		case c.Cmp(&minusOne) == 0:
+			res[i].SetCoeffValue(-1)
+		}
+	}
+	return res
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1c
+
+import "strings"
+
+// Component describes the constraints added by a sub-circuit (see frontend.ConstraintSystem.Define)
+//
+// components may be nested, the constraints of a component include the constraints of its children.
+// Components are ordered as they are defined: a parent comes before its children.
+type Component struct {
+	Name        string
+	Parent      int    // index of the parent component, -1 for a top-level component
+	Constraints [2]int // computational constraints of the component, [start, end) in the R1CS constraints
+	Assertions  [2]int // assertions of the component, [start, end) in the R1CS constraints
+}
+
+// NbCOConstraints returns the number of computational constraints of the component
+func (c *Component) NbCOConstraints() int {
+	return c.Constraints[1] - c.Constraints[0]
+}
+
+// NbAssertions returns the number of assertions of the component
+func (c *Component) NbAssertions() int {
+	return c.Assertions[1] - c.Assertions[0]
+}
+
+// Contains returns true if the constraint with index constraintID belongs to the component
+func (c *Component) Contains(constraintID int) bool {
+	return (constraintID >= c.Constraints[0] && constraintID < c.Constraints[1]) ||
+		(constraintID >= c.Assertions[0] && constraintID < c.Assertions[1])
+}
+
+// FindComponent returns the index of the innermost component containing the constraint
+// with index constraintID, or -1 if the constraint doesn't belong to a component
+func FindComponent(components []Component, constraintID int) int {
+	// the components containing a constraint are nested, the innermost one is defined last
+	for i := len(components) - 1; i >= 0; i-- {
+		if components[i].Contains(constraintID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ComponentPath returns the names of the component with index id and of its parents ("parent/child")
+func ComponentPath(components []Component, id int) string {
+	var names []string
+	for ; id != -1; id = components[id].Parent {
+		names = append(names, components[id].Name)
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, "/")
+}
@@ -14,6 +14,8 @@
 
 package r1c
 
+import "github.com/consensys/gnark/backend/hint"
+
 // LinearExpression represent a linear expression of variables
 type LinearExpression []Term
 
@@ -34,3 +36,12 @@ const (
 	SingleOutput SolvingMethod = iota
 	BinaryDec
 )
+
+// Hint describes a wire which is computed by a hint function when solving the R1CS
+// (see package backend/hint). The wire is not tied to a R1C: it is computed the first time
+// the solver needs its value.
+type Hint struct {
+	ID     hint.ID            // ID of the hint function (see hint.UUID)
+	WireID int                // ID of the wire computed by the hint
+	Inputs []LinearExpression // inputs of the hint function
+}
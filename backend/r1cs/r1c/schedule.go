@@ -0,0 +1,138 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1c
+
+// Schedule groups the hints and the computational constraints of a R1CS by dependency levels:
+// the items of a level only depend on the inputs and on the wires computed by the previous levels,
+// so a solver can compute them concurrently.
+//
+// The sequence numbers give the position of each item in the sequential solving order, where a hint
+// is computed the first time a constraint needs its wire. A parallel solver uses them to stop where
+// a sequential solver would, and to report the same error.
+type Schedule struct {
+	Levels        []Level
+	HintSeq       []int // sequence number of each hint
+	ConstraintSeq []int // sequence number of each computational constraint
+	WireSeq       []int // sequence number of the hint or constraint computing each wire, -1 for the inputs
+}
+
+// Level is a set of hints and computational constraints which can be solved concurrently
+type Level struct {
+	Hints       []int // indexes in the hints of the R1CS
+	Constraints []int // indexes in the constraints of the R1CS
+}
+
+// NewSchedule computes the Schedule of a R1CS with nbWires wires, the first nbInternalWires of which
+// are computed by the constraints (the others are the inputs), and with the given computational constraints
+// and hints
+func NewSchedule(nbWires, nbInternalWires int, constraints []R1C, hints []Hint) *Schedule {
+	s := &Schedule{
+		HintSeq:       make([]int, len(hints)),
+		ConstraintSeq: make([]int, len(constraints)),
+		WireSeq:       make([]int, nbWires),
+	}
+
+	// depth of a computed wire: 0 for the inputs, 1 + the level of the item computing it otherwise
+	wireDepth := make([]int, nbWires)
+	computed := make([]bool, nbWires)
+	for i := 0; i < nbWires; i++ {
+		s.WireSeq[i] = -1
+		computed[i] = i >= nbInternalWires
+	}
+
+	hintWires := make(map[int]int, len(hints)) // wireID -> index in hints
+	for i := 0; i < len(hints); i++ {
+		hintWires[hints[i].WireID] = i
+	}
+
+	seq := 0
+	level := func(depth int) *Level {
+		for len(s.Levels) <= depth {
+			s.Levels = append(s.Levels, Level{})
+		}
+		return &s.Levels[depth]
+	}
+
+	// inputDepth returns the maximum depth of the computed wires of le, scheduling the
+	// hints computing its wires first, as the sequential solver does
+	var scheduleHint func(i int)
+	inputDepth := func(le LinearExpression) (depth int) {
+		for _, t := range le {
+			wireID := t.VariableID()
+			if !computed[wireID] {
+				i, ok := hintWires[wireID]
+				if !ok {
+					continue
+				}
+				scheduleHint(i)
+			}
+			if wireDepth[wireID] > depth {
+				depth = wireDepth[wireID]
+			}
+		}
+		return
+	}
+	scheduleHint = func(i int) {
+		h := &hints[i]
+		depth := 0
+		for _, le := range h.Inputs {
+			if d := inputDepth(le); d > depth {
+				depth = d
+			}
+		}
+		l := level(depth)
+		l.Hints = append(l.Hints, i)
+		s.HintSeq[i] = seq
+		s.WireSeq[h.WireID] = seq
+		seq++
+		computed[h.WireID] = true
+		wireDepth[h.WireID] = depth + 1
+	}
+
+	for i := 0; i < len(constraints); i++ {
+		r := &constraints[i]
+		depth := 0
+		for _, le := range [3]LinearExpression{r.L, r.R, r.O} {
+			if d := inputDepth(le); d > depth {
+				depth = d
+			}
+		}
+		l := level(depth)
+		l.Constraints = append(l.Constraints, i)
+		s.ConstraintSeq[i] = seq
+
+		// the wires which are not computed yet are the outputs of the constraint
+		for _, le := range [3]LinearExpression{r.L, r.R, r.O} {
+			for _, t := range le {
+				wireID := t.VariableID()
+				if !computed[wireID] {
+					computed[wireID] = true
+					wireDepth[wireID] = depth + 1
+					s.WireSeq[wireID] = seq
+				}
+			}
+		}
+		seq++
+	}
+
+	// some hint wires may only appear in assertions
+	for i := 0; i < len(hints); i++ {
+		if !computed[hints[i].WireID] {
+			scheduleHint(i)
+		}
+	}
+
+	return s
+}
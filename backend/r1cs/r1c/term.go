@@ -14,13 +14,25 @@
 
 package r1c
 
-import "github.com/consensys/gnark/backend"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/backend"
+)
 
 // Term lightweight version of a term, no pointers
-// first 4 bits are reserved
-// next 30 bits represented the coefficient idx (in r1cs.Coefficients) by which the wire is multiplied
-// next 30 bits represent the constraint used to compute the wire
-// if we support more than 1 billion constraints, this breaks (not so soon.)
+//
+// the 64 bits of a Term encode, from the least significant bits:
+//   - the ID of the wire (29 bits)
+//   - the index of the coefficient in the R1CS coefficients (30 bits)
+//   - a special value of the coefficient, if any: -1, 0, 1 or 2 (3 bits)
+//   - the visibility of the wire (2 bits)
+//
+// a wide term trades coefficient bits for wire bits: the wire ID has 34 bits and the coefficient
+// index 25 bits, while the special value bits mark the term as wide (its coefficient is always read
+// from the R1CS coefficients). Only the circuits compiled with frontend.WithWideTerms have wide terms,
+// for the wire IDs which don't fit in a term (see CheckCapacity).
 type Term uint64
 
 const (
@@ -29,6 +41,7 @@ const (
 	coeffValueZero     uint64 = 0b010
 	coeffValueOne      uint64 = 0b011
 	coeffValueTwo      uint64 = 0b100
+	coeffValueWide     uint64 = 0b111 // not a value: marks a wide term
 )
 
 const (
@@ -41,6 +54,8 @@ const (
 const (
 	nbBitsVariableID           = 29
 	nbBitsCoeffID              = 30
+	nbBitsWideVariableID       = 34
+	nbBitsWideCoeffID          = nbBitsVariableID + nbBitsCoeffID - nbBitsWideVariableID
 	nbBitsCoeffValue           = 3
 	nbBitsConstraintVisibility = 2
 )
@@ -48,6 +63,7 @@ const (
 const (
 	shiftVariableID           = 0
 	shiftCoeffID              = nbBitsVariableID
+	shiftWideCoeffID          = nbBitsWideVariableID
 	shiftCoeffValue           = shiftCoeffID + nbBitsCoeffID
 	shiftConstraintVisibility = shiftCoeffValue + nbBitsCoeffValue
 )
@@ -55,15 +71,42 @@ const (
 const (
 	maskVariableID           = uint64((1 << nbBitsVariableID) - 1)
 	maskCoeffID              = uint64((1<<nbBitsCoeffID)-1) << shiftCoeffID
+	maskWideVariableID       = uint64((1 << nbBitsWideVariableID) - 1)
+	maskWideCoeffID          = uint64((1<<nbBitsWideCoeffID)-1) << shiftWideCoeffID
 	maskCoeffValue           = uint64((1<<nbBitsCoeffValue)-1) << shiftCoeffValue
 	maskConstraintVisibility = uint64((1<<nbBitsConstraintVisibility)-1) << shiftConstraintVisibility
 )
 
-// Pack packs constraintID, coeffID and coeffValue into Term
-// first 5 bits are reserved to encode visibility of the constraint and coeffValue of the coefficient
-// next 30 bits represented the coefficient idx (in r1cs.Coefficients) by which the wire is multiplied
-// next 29 bits represent the constraint used to compute the wire
-// if we support more than 500 millions constraints, this breaks (not so soon.)
+// maximum number of wires and coefficients of a R1CS, with and without wide terms
+const (
+	MaxWires            = 1 << nbBitsVariableID
+	MaxCoefficients     = 1 << nbBitsCoeffID
+	MaxWideWires        = 1 << nbBitsWideVariableID
+	MaxWideCoefficients = 1 << nbBitsWideCoeffID
+)
+
+// ErrTermOverflow is returned when the wire or coefficient IDs of a circuit don't fit in a Term
+var ErrTermOverflow = errors.New("too many wires or coefficients for r1c.Term")
+
+// CheckCapacity returns an error wrapping ErrTermOverflow if the IDs of nbWires wires and
+// nbCoefficients coefficients don't fit in the terms, wide terms being allowed or not
+func CheckCapacity(nbWires, nbCoefficients int, wide bool) error {
+	if nbWires <= MaxWires && nbCoefficients <= MaxCoefficients {
+		return nil
+	}
+	if !wide {
+		return fmt.Errorf("%w: %d wires and %d coefficients, the limits are %d and %d (or %d and %d with wide terms)",
+			ErrTermOverflow, nbWires, nbCoefficients, MaxWires, MaxCoefficients, MaxWideWires, MaxWideCoefficients)
+	}
+	// any term may be wide
+	if nbWires > MaxWideWires || nbCoefficients > MaxWideCoefficients {
+		return fmt.Errorf("%w: %d wires and %d coefficients, the limits with wide terms are %d and %d",
+			ErrTermOverflow, nbWires, nbCoefficients, MaxWideWires, MaxWideCoefficients)
+	}
+	return nil
+}
+
+// Pack packs constraintID, coeffID and coeffValue into Term (see Term for the encoding)
 func Pack(constraintID, coeffID int, constraintVisibility backend.Visibility, coeffValue ...int) Term {
 	var t Term
 	t.SetVariableID(constraintID)
@@ -75,6 +118,16 @@ func Pack(constraintID, coeffID int, constraintVisibility backend.Visibility, co
 	return t
 }
 
+// PackWide packs constraintID, coeffID and constraintVisibility into a wide Term (see Term for the encoding)
+func PackWide(constraintID, coeffID int, constraintVisibility backend.Visibility) Term {
+	var t Term
+	t.Widen()
+	t.SetVariableID(constraintID)
+	t.SetCoeffID(coeffID)
+	t.SetConstraintVisibility(constraintVisibility)
+	return t
+}
+
 // Unpack returns coeffValue, coeffID and constraintID
 func (t Term) Unpack() (coeffValue, coeffID, constraintID int, constraintVisibility backend.Visibility) {
 	coeffValue = t.CoeffValue()
@@ -84,7 +137,7 @@ func (t Term) Unpack() (coeffValue, coeffID, constraintID int, constraintVisibil
 	return
 }
 
-// CoeffValue return maxInt if no special value is set
+// CoeffValue return maxInt if no special value is set (which is always the case for a wide term)
 // if set, returns either -1, 0, 1 or 2
 func (t Term) CoeffValue() int {
 	coeffValue := (uint64(t) & maskCoeffValue) >> shiftCoeffValue
@@ -136,7 +189,11 @@ func (t *Term) SetConstraintVisibility(v backend.Visibility) {
 }
 
 // SetCoeffValue update the bits correponding to the coeffValue with its encoding
+// a wide term has no special value, the call has no effect
 func (t *Term) SetCoeffValue(val int) {
+	if t.IsWide() {
+		return
+	}
 	coeffValue := uint64(0)
 	switch val {
 	case -1:
@@ -156,29 +213,64 @@ func (t *Term) SetCoeffValue(val int) {
 
 // SetCoeffID update the bits correponding to the coeffID with cID
 func (t *Term) SetCoeffID(cID int) {
-	_coeffID := uint64(cID)
-	if (_coeffID & (maskCoeffID >> shiftCoeffID)) != uint64(cID) {
+	if t.IsWide() {
+		if cID < 0 || cID >= MaxWideCoefficients {
+			panic("coeffID is too large for a wide term, unsupported")
+		}
+		*t = Term((uint64(*t) & (^maskWideCoeffID)) | (uint64(cID) << shiftWideCoeffID))
+		return
+	}
+	if cID < 0 || cID >= MaxCoefficients {
 		panic("coeffID is too large, unsupported")
 	}
-	_coeffID <<= shiftCoeffID
-	*t = Term((uint64(*t) & (^maskCoeffID)) | _coeffID)
+	*t = Term((uint64(*t) & (^maskCoeffID)) | (uint64(cID) << shiftCoeffID))
 }
 
 // SetVariableID update the bits correponding to the constraintID with cID
+// cID must fit in the term (a wire ID of a wide term has more bits, see Widen)
 func (t *Term) SetVariableID(cID int) {
-	_constraintID := uint64(cID)
-	if (_constraintID & maskVariableID) != uint64(cID) {
+	if t.IsWide() {
+		if cID < 0 || cID >= MaxWideWires {
+			panic("constraintID is too large for a wide term, unsupported")
+		}
+		*t = Term((uint64(*t) & (^maskWideVariableID)) | uint64(cID))
+		return
+	}
+	if cID < 0 || cID >= MaxWires {
 		panic("constraintID is too large, unsupported")
 	}
-	*t = Term((uint64(*t) & (^maskVariableID)) | _constraintID)
+	*t = Term((uint64(*t) & (^maskVariableID)) | uint64(cID))
+}
+
+// Widen converts the term to a wide term, dropping the special value of its coefficient
+// (see Term). Its coefficient ID must fit in a wide term
+func (t *Term) Widen() {
+	if t.IsWide() {
+		return
+	}
+	variableID, coeffID := t.VariableID(), t.CoeffID()
+	*t = Term((uint64(*t) & (^maskCoeffValue)) | (coeffValueWide << shiftCoeffValue))
+	t.SetVariableID(variableID)
+	t.SetCoeffID(coeffID)
+}
+
+// IsWide returns true if the term uses the wide encoding (see Term)
+func (t Term) IsWide() bool {
+	return (uint64(t)&maskCoeffValue)>>shiftCoeffValue == coeffValueWide
 }
 
 // VariableID returns the constraintID (see R1CS data structure)
 func (t Term) VariableID() int {
+	if t.IsWide() {
+		return int(uint64(t) & maskWideVariableID)
+	}
 	return int((uint64(t) & maskVariableID))
 }
 
 // CoeffID returns the coefficient id (see R1CS data structure)
 func (t Term) CoeffID() int {
+	if t.IsWide() {
+		return int((uint64(t) & maskWideCoeffID) >> shiftWideCoeffID)
+	}
 	return int((uint64(t) & maskCoeffID) >> shiftCoeffID)
 }
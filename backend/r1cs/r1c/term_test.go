@@ -0,0 +1,69 @@
+package r1c
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+)
+
+func TestTerm(t *testing.T) {
+	expectPanic := func(f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic")
+			}
+		}()
+		f()
+	}
+
+	term := Pack(42, 7, backend.Secret, 2)
+	if term.IsWide() || term.VariableID() != 42 || term.CoeffID() != 7 || term.CoeffValue() != 2 || term.ConstraintVisibility() != backend.Secret {
+		t.Fatalf("unexpected term %#x", uint64(term))
+	}
+
+	// a wire ID which doesn't fit in 29 bits needs a wide term
+	expectPanic(func() { term.SetVariableID(MaxWires + 5) })
+	term.Widen()
+	term.SetVariableID(MaxWires + 5)
+	if !term.IsWide() || term.VariableID() != MaxWires+5 || term.CoeffID() != 7 || term.ConstraintVisibility() != backend.Secret {
+		t.Fatalf("unexpected wide term %#x", uint64(term))
+	}
+	if term.CoeffValue() == 2 {
+		t.Fatal("a wide term has no special coefficient value")
+	}
+	term.SetCoeffValue(1)
+	term.SetCoeffID(MaxWideCoefficients - 1)
+	if !term.IsWide() || term.VariableID() != MaxWires+5 || term.CoeffID() != MaxWideCoefficients-1 {
+		t.Fatalf("unexpected wide term %#x", uint64(term))
+	}
+
+	term = PackWide(MaxWideWires-1, MaxWideCoefficients-1, backend.Public)
+	if term.VariableID() != MaxWideWires-1 || term.CoeffID() != MaxWideCoefficients-1 || term.ConstraintVisibility() != backend.Public {
+		t.Fatalf("unexpected wide term %#x", uint64(term))
+	}
+
+	expectPanic(func() { Pack(MaxWires, 0, backend.Internal) })
+	expectPanic(func() { PackWide(MaxWires, MaxWideCoefficients, backend.Internal) })
+	expectPanic(func() { PackWide(MaxWideWires, 0, backend.Internal) })
+	expectPanic(func() { Pack(0, MaxCoefficients, backend.Internal) })
+}
+
+func TestCheckCapacity(t *testing.T) {
+	if err := CheckCapacity(MaxWires, MaxCoefficients, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCapacity(MaxWires+1, 10, false); !errors.Is(err, ErrTermOverflow) {
+		t.Fatal("expected ErrTermOverflow, got", err)
+	}
+	if err := CheckCapacity(MaxWires+1, 10, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCapacity(MaxWires+1, MaxWideCoefficients+1, true); !errors.Is(err, ErrTermOverflow) {
+		t.Fatal("expected ErrTermOverflow, got", err)
+	}
+	if err := CheckCapacity(MaxWideWires+1, 10, true); !errors.Is(err, ErrTermOverflow) {
+		t.Fatal("expected ErrTermOverflow, got", err)
+	}
+}
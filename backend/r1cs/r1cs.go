@@ -17,7 +17,10 @@ package r1cs
 
 import (
 	"io"
+	"math/big"
 
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
 	backend_bls377 "github.com/consensys/gnark/internal/backend/bls377"
 	backend_bls381 "github.com/consensys/gnark/internal/backend/bls381"
 	backend_bn256 "github.com/consensys/gnark/internal/backend/bn256"
@@ -31,10 +34,13 @@ type R1CS interface {
 	io.WriterTo
 	io.ReaderFrom
 	IsSolved(solution map[string]interface{}) error
+	Solution(assignment map[string]interface{}) (*backend.Solution, error)
 	GetNbConstraints() uint64
 	GetNbWires() uint64
 	GetNbCoefficients() int
 	GetCurveID() gurvy.ID
+	GetComponents() []r1c.Component
+	GetInputNames() (secret, public []string)
 }
 
 // New instantiate a concrete curved-typed R1CS and return a R1CS interface
@@ -55,3 +61,52 @@ func New(curveID gurvy.ID) R1CS {
 	}
 	return r1cs
 }
+
+// toUntyped returns the R1CS as an UntypedR1CS, and the modulus of its field (nil if it is untyped)
+func toUntyped(r1cs R1CS) (*UntypedR1CS, *big.Int) {
+	switch _r1cs := r1cs.(type) {
+	case *UntypedR1CS:
+		return _r1cs, nil
+	case *backend_bn256.R1CS:
+		return fromBN256(_r1cs), FieldModulus(gurvy.BN256)
+	case *backend_bls377.R1CS:
+		return fromBLS377(_r1cs), FieldModulus(gurvy.BLS377)
+	case *backend_bls381.R1CS:
+		return fromBLS381(_r1cs), FieldModulus(gurvy.BLS381)
+	case *backend_bw761.R1CS:
+		return fromBW761(_r1cs), FieldModulus(gurvy.BW761)
+	default:
+		panic("not implemented")
+	}
+}
+
+// ComponentStats aggregates the constraints of the instances of a component
+// (see frontend.ConstraintSystem.Define)
+type ComponentStats struct {
+	Path            string // names of the component and of its parents ("parent/child")
+	NbInstances     int
+	NbCOConstraints int // computational constraints of all the instances
+	NbAssertions    int // assertions of all the instances
+}
+
+// GetComponentStats returns the statistics of the components of the R1CS, by path,
+// in the order they were first defined
+func GetComponentStats(r1cs R1CS) []ComponentStats {
+	components := r1cs.GetComponents()
+
+	var res []ComponentStats
+	index := make(map[string]int)
+	for i := 0; i < len(components); i++ {
+		path := r1c.ComponentPath(components, i)
+		j, ok := index[path]
+		if !ok {
+			j = len(res)
+			index[path] = j
+			res = append(res, ComponentStats{Path: path})
+		}
+		res[j].NbInstances++
+		res[j].NbCOConstraints += components[i].NbCOConstraints()
+		res[j].NbAssertions += components[i].NbAssertions()
+	}
+	return res
+}
@@ -17,6 +17,10 @@
 package r1cs
 
 import (
+	"math/big"
+
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+
 	bn256backend "github.com/consensys/gnark/internal/backend/bn256"
 
 	"github.com/consensys/gurvy/bn256/fr"
@@ -36,6 +40,11 @@ func (r1cs *UntypedR1CS) toBN256() *bn256backend.R1CS {
 		Coefficients:    make([]fr.Element, len(r1cs.Coefficients)),
 		Logs:            r1cs.Logs,
 		DebugInfo:       r1cs.DebugInfo,
+		Hints:           r1cs.Hints,
+		Components:      r1cs.Components,
+		CallStacks:      r1cs.CallStacks,
+		CallStackIDs:    r1cs.CallStackIDs,
+		Schedule:        r1c.NewSchedule(int(r1cs.NbWires), int(r1cs.NbWires-r1cs.NbPublicWires-r1cs.NbSecretWires), r1cs.Constraints[:r1cs.NbCOConstraints], r1cs.Hints),
 	}
 
 	for i := 0; i < len(r1cs.Coefficients); i++ {
@@ -44,3 +53,31 @@ func (r1cs *UntypedR1CS) toBN256() *bn256backend.R1CS {
 
 	return &toReturn
 }
+
+// fromBN256 returns the untyped R1CS of a typed R1CS, its coefficients are in regular form
+func fromBN256(r1cs *bn256backend.R1CS) *UntypedR1CS {
+
+	toReturn := UntypedR1CS{
+		NbWires:         r1cs.NbWires,
+		NbPublicWires:   r1cs.NbPublicWires,
+		NbSecretWires:   r1cs.NbSecretWires,
+		SecretWires:     r1cs.SecretWires,
+		PublicWires:     r1cs.PublicWires,
+		NbConstraints:   r1cs.NbConstraints,
+		NbCOConstraints: r1cs.NbCOConstraints,
+		Constraints:     r1cs.Constraints,
+		Coefficients:    make([]big.Int, len(r1cs.Coefficients)),
+		Logs:            r1cs.Logs,
+		DebugInfo:       r1cs.DebugInfo,
+		Hints:           r1cs.Hints,
+		Components:      r1cs.Components,
+		CallStacks:      r1cs.CallStacks,
+		CallStackIDs:    r1cs.CallStackIDs,
+	}
+
+	for i := 0; i < len(r1cs.Coefficients); i++ {
+		r1cs.Coefficients[i].ToBigIntRegular(&toReturn.Coefficients[i])
+	}
+
+	return &toReturn
+}
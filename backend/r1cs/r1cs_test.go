@@ -0,0 +1,119 @@
+package r1cs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type solutionCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *solutionCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	x3 := cs.Mul(c.X, c.X, c.X)
+	cs.AssertIsEqual(c.Y, cs.Add(x3, c.X, 5))
+	return nil
+}
+
+func TestSolution(t *testing.T) {
+	var circuit solutionCircuit
+	res, err := frontend.Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solution, err := res.Solution(map[string]interface{}{"X": 3, "Y": 35})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solution.Values) != int(res.GetNbWires()) || len(solution.Names) != len(solution.Values) {
+		t.Fatal("expected the values of all the wires")
+	}
+	if x, ok := solution.Value("X"); !ok || x.Int64() != 3 {
+		t.Fatal("unexpected value of X", x)
+	}
+	if one, ok := solution.Value(backend.OneWire); !ok || one.Int64() != 1 {
+		t.Fatal("unexpected value of the ONE wire", one)
+	}
+	if _, ok := solution.Value("Z"); ok {
+		t.Fatal("Z is not an input")
+	}
+
+	// the internal wires hold the intermediate values
+	found := map[int64]bool{}
+	for i := 0; i < solution.NbInternalWires; i++ {
+		if solution.Names[i] != "" {
+			t.Fatal("internal wires have no name")
+		}
+		found[solution.Values[i].Int64()] = true
+	}
+	if !found[9] || !found[27] {
+		t.Fatal("expected 3^2 and 3^3 in the internal wires, got", solution.Values)
+	}
+
+	if _, err := res.Solution(map[string]interface{}{"X": 3, "Y": 36}); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error, got", err)
+	}
+}
+
+type squareComponent struct {
+	X, Square frontend.Variable
+}
+
+func (c *squareComponent) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	c.Square = cs.Mul(c.X, c.X)
+	return nil
+}
+
+type sumOfSquaresComponent struct {
+	X, Y, Expected frontend.Variable
+}
+
+func (c *sumOfSquaresComponent) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	x2 := squareComponent{X: c.X}
+	if err := cs.Define("square", &x2); err != nil {
+		return err
+	}
+	y2 := squareComponent{X: c.Y}
+	if err := cs.Define("square", &y2); err != nil {
+		return err
+	}
+	cs.AssertIsEqual(cs.Add(x2.Square, y2.Square), c.Expected)
+	return nil
+}
+
+type componentCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *componentCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	cs.AssertIsEqual(c.X, cs.Mul(c.Y, 1))
+	return cs.Define("sumOfSquares", &sumOfSquaresComponent{X: c.X, Y: c.Y, Expected: c.Z})
+}
+
+func TestGetComponentStats(t *testing.T) {
+	for _, curveID := range []gurvy.ID{gurvy.UNKNOWN, gurvy.BN256} {
+		res, err := frontend.Compile(curveID, &componentCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the instances of a component are aggregated, the constraints outside of the components are not counted
+		stats := r1cs.GetComponentStats(res)
+		expected := []r1cs.ComponentStats{
+			{Path: "sumOfSquares", NbInstances: 1, NbCOConstraints: 2, NbAssertions: 1},
+			{Path: "sumOfSquares/square", NbInstances: 2, NbCOConstraints: 2, NbAssertions: 0},
+		}
+		if !reflect.DeepEqual(stats, expected) {
+			t.Fatal(curveID.String(), "unexpected component stats", stats)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r1cs
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+)
+
+// ErrNoModulus is returned when solving an UntypedR1CS without a modulus (see UntypedR1CS.SolutionModulo)
+var ErrNoModulus = errors.New("untyped R1CS has no field modulus")
+
+// solver solves an UntypedR1CS modulo a prime, following the same steps as the typed solvers
+// (hints are computed the first time a constraint needs them)
+type solver struct {
+	r1cs         *UntypedR1CS
+	modulus      *big.Int
+	coefficients []big.Int // coefficients of the R1CS, reduced modulo the modulus
+	values       []big.Int // wire values
+	instantiated []bool    // true if the wire has a value
+	hintWires    map[int]int
+}
+
+func newSolver(r1cs *UntypedR1CS, modulus *big.Int) (*solver, error) {
+	if modulus == nil || modulus.Sign() <= 0 || !modulus.ProbablyPrime(20) {
+		return nil, errors.New("the modulus must be a prime")
+	}
+	s := &solver{
+		r1cs:         r1cs,
+		modulus:      modulus,
+		coefficients: make([]big.Int, len(r1cs.Coefficients)),
+		values:       make([]big.Int, r1cs.NbWires),
+		instantiated: make([]bool, r1cs.NbWires),
+		hintWires:    make(map[int]int, len(r1cs.Hints)),
+	}
+	for i := 0; i < len(r1cs.Coefficients); i++ {
+		s.coefficients[i].Mod(&r1cs.Coefficients[i], modulus)
+	}
+	for i := 0; i < len(r1cs.Hints); i++ {
+		s.hintWires[r1cs.Hints[i].WireID] = i
+	}
+	return s, nil
+}
+
+// instantiateInputs sets the values of the input wires from the assignment
+func (s *solver) instantiateInputs(assignment map[string]interface{}) error {
+	instantiate := func(offset int, names []string) error {
+		for i := 0; i < len(names); i++ {
+			if names[i] == backend.OneWire {
+				s.values[offset+i].SetUint64(1)
+				s.instantiated[offset+i] = true
+				continue
+			}
+			v, ok := assignment[names[i]]
+			if !ok {
+				return fmt.Errorf("%q: %w", names[i], backend.ErrInputNotSet)
+			}
+			value := backend.FromInterface(v)
+			s.values[offset+i].Mod(&value, s.modulus)
+			s.instantiated[offset+i] = true
+		}
+		return nil
+	}
+	offset := int(s.r1cs.NbWires - s.r1cs.NbPublicWires - s.r1cs.NbSecretWires)
+	if err := instantiate(offset, s.r1cs.SecretWires); err != nil {
+		return err
+	}
+	return instantiate(int(s.r1cs.NbWires-s.r1cs.NbPublicWires), s.r1cs.PublicWires)
+}
+
+func (s *solver) solve() error {
+	r1cs := s.r1cs
+	defer s.printLogs()
+
+	// Loop through computational constraints (the one we need to solve and compute a wire in)
+	var check big.Int
+	for i := 0; i < int(r1cs.NbCOConstraints); i++ {
+		r := &r1cs.Constraints[i]
+		for _, le := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+			if err := s.solveHints(le); err != nil {
+				return err
+			}
+		}
+		s.solveR1C(r)
+
+		a, b, c := s.instantiateR1C(r)
+		if s.mul(&check, &a, &b).Cmp(&c) != 0 {
+			return s.unsatisfiedConstraint(i, &a, &b, &c)
+		}
+	}
+
+	// some hint wires may only appear in assertions
+	for i := 0; i < len(r1cs.Hints); i++ {
+		if !s.instantiated[r1cs.Hints[i].WireID] {
+			if err := s.solveHint(&r1cs.Hints[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Loop through the assertions -- here all the wires should be instantiated
+	for i := int(r1cs.NbCOConstraints); i < len(r1cs.Constraints); i++ {
+		a, b, c := s.instantiateR1C(&r1cs.Constraints[i])
+		if s.mul(&check, &a, &b).Cmp(&c) != 0 {
+			return s.unsatisfiedConstraint(i, &a, &b, &c)
+		}
+	}
+
+	return nil
+}
+
+// solveHints instantiates the wires of le which are computed by a hint function and are not instantiated yet
+func (s *solver) solveHints(le r1c.LinearExpression) error {
+	for _, t := range le {
+		wireID := t.VariableID()
+		if s.instantiated[wireID] {
+			continue
+		}
+		if i, ok := s.hintWires[wireID]; ok {
+			if err := s.solveHint(&s.r1cs.Hints[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// solveHint evaluates the inputs of the hint, calls the hint function and instantiates the wire it computes
+func (s *solver) solveHint(h *r1c.Hint) error {
+	f, ok := hint.Lookup(h.ID)
+	if !ok {
+		return fmt.Errorf("%w (id %d)", hint.ErrNotRegistered, h.ID)
+	}
+
+	inputs := make([]*big.Int, len(h.Inputs))
+	for i, le := range h.Inputs {
+		// an input may itself be computed by a hint function
+		if err := s.solveHints(le); err != nil {
+			return err
+		}
+		for _, t := range le {
+			if !s.instantiated[t.VariableID()] {
+				return errors.New("hint input is not instantiated")
+			}
+		}
+		inputs[i] = new(big.Int)
+		s.evaluate(inputs[i], le)
+	}
+
+	var result big.Int
+	if err := f(s.modulus, inputs, &result); err != nil {
+		return err
+	}
+	s.values[h.WireID].Mod(&result, s.modulus)
+	s.instantiated[h.WireID] = true
+
+	return nil
+}
+
+// solveR1C computes the wires of the constraint which are not instantiated yet
+func (s *solver) solveR1C(r *r1c.R1C) {
+	switch r.Solver {
+
+	// in this case we solve a R1C by isolating the uncomputed wire
+	case r1c.SingleOutput:
+		var loc uint8 // 1, 2 or 3 if the uninstantiated wire is in L, R or O
+		var termToCompute r1c.Term
+		var v [3]big.Int // values of L, R and O, without the uninstantiated wire
+
+		for j, le := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+			for _, t := range le {
+				if s.instantiated[t.VariableID()] {
+					s.addTerm(&v[j], t)
+					continue
+				}
+				if loc != 0 {
+					panic("found more than one wire to instantiate")
+				}
+				termToCompute = t
+				loc = uint8(j + 1)
+			}
+		}
+
+		// the wire may already have been instantiated
+		if loc == 0 {
+			return
+		}
+
+		wireID := termToCompute.VariableID()
+		value := &s.values[wireID]
+		a, b, c := &v[0], &v[1], &v[2]
+		switch loc {
+		case 1:
+			if b.Sign() != 0 {
+				s.div(value, c, b).Sub(value, a)
+				s.mul(value, value, &s.coefficients[termToCompute.CoeffID()])
+			}
+		case 2:
+			if a.Sign() != 0 {
+				s.div(value, c, a).Sub(value, b)
+				s.mul(value, value, &s.coefficients[termToCompute.CoeffID()])
+			}
+		case 3:
+			s.mul(value, a, b).Sub(value, c)
+			s.mul(value, value, &s.coefficients[termToCompute.CoeffID()])
+		}
+		s.instantiated[wireID] = true
+
+	// in this case the R1C is solved by directly computing the binary decomposition of O
+	case r1c.BinaryDec:
+		var n big.Int
+		s.evaluate(&n, r.O)
+
+		nbBits := len(r.L)
+		for _, t := range r.L {
+			// the coefficient of the wire is 2^i for the i-th bit; it is read before its reduction modulo
+			// s.modulus, as 2^i may be congruent to a smaller power of 2 in a small field. If it is not a
+			// power of 2, it has been reduced at compile time (see UntypedR1CS.Optimize), and the bit is 0
+			coeff := &s.r1cs.Coefficients[t.CoeffID()]
+			i := coeff.BitLen() - 1
+			if i < 0 || coeff.Cmp(new(big.Int).Lsh(bOne, uint(i))) != 0 {
+				i = nbBits - 1
+			}
+			wireID := t.VariableID()
+			s.values[wireID].SetUint64(uint64(n.Bit(i)))
+			s.instantiated[wireID] = true
+		}
+
+	default:
+		panic("unimplemented solving method")
+	}
+}
+
+// instantiateR1C returns the values of the linear expressions of the constraint
+func (s *solver) instantiateR1C(r *r1c.R1C) (a, b, c big.Int) {
+	s.evaluate(&a, r.L)
+	s.evaluate(&b, r.R)
+	s.evaluate(&c, r.O)
+	return
+}
+
+// evaluate sets res to the value of le, the uninstantiated wires are zero
+func (s *solver) evaluate(res *big.Int, le r1c.LinearExpression) *big.Int {
+	res.SetUint64(0)
+	for _, t := range le {
+		s.addTerm(res, t)
+	}
+	return res
+}
+
+// addTerm returns res += value * coefficient of the term, modulo the modulus
+func (s *solver) addTerm(res *big.Int, t r1c.Term) *big.Int {
+	var buffer big.Int
+	buffer.Mul(&s.values[t.VariableID()], &s.coefficients[t.CoeffID()])
+	return res.Add(res, &buffer).Mod(res, s.modulus)
+}
+
+func (s *solver) mul(res, a, b *big.Int) *big.Int {
+	return res.Mul(a, b).Mod(res, s.modulus)
+}
+
+// div returns res = a / b, b must not be zero
+func (s *solver) div(res, a, b *big.Int) *big.Int {
+	var inverse big.Int
+	inverse.ModInverse(b, s.modulus)
+	return s.mul(res, a, &inverse)
+}
+
+// unsatisfiedConstraint returns a *backend.UnsatisfiedConstraintError describing the i-th constraint,
+// where a*b != c
+func (s *solver) unsatisfiedConstraint(i int, a, b, c *big.Int) error {
+	r1cs := s.r1cs
+	err := &backend.UnsatisfiedConstraintError{
+		ConstraintID: i,
+		Assertion:    i >= int(r1cs.NbCOConstraints),
+		L:            a.String(),
+		R:            b.String(),
+		O:            c.String(),
+		Wires:        make(map[int]string),
+	}
+
+	// debug info may have been stripped at compile time
+	if err.Assertion && len(r1cs.DebugInfo) != 0 {
+		err.DebugInfo = s.logValue(r1cs.DebugInfo[i-int(r1cs.NbCOConstraints)])
+	}
+
+	r := &r1cs.Constraints[i]
+	for _, le := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+		for _, t := range le {
+			err.Wires[t.VariableID()] = s.wireValue(t.VariableID())
+		}
+	}
+
+	if len(r1cs.CallStackIDs) != 0 && r1cs.CallStackIDs[i] != -1 {
+		err.Stack = r1cs.CallStacks[r1cs.CallStackIDs[i]]
+	}
+
+	if id := r1c.FindComponent(r1cs.Components, i); id != -1 {
+		err.Component = r1c.ComponentPath(r1cs.Components, id)
+	}
+
+	return err
+}
+
+func (s *solver) wireValue(wireID int) string {
+	if !s.instantiated[wireID] {
+		// solving stopped before this wire was computed
+		return "<unsolved>"
+	}
+	return s.values[wireID].String()
+}
+
+func (s *solver) logValue(entry backend.LogEntry) string {
+	toResolve := make([]interface{}, len(entry.ToResolve))
+	for j := 0; j < len(entry.ToResolve); j++ {
+		toResolve[j] = s.wireValue(entry.ToResolve[j])
+	}
+	return fmt.Sprintf(entry.Format, toResolve...)
+}
+
+func (s *solver) printLogs() {
+	for i := 0; i < len(s.r1cs.Logs); i++ {
+		fmt.Print(s.logValue(s.r1cs.Logs[i]))
+	}
+}
@@ -18,8 +18,12 @@ import (
 	"io"
 	"math/big"
 
+	"github.com/fxamacker/cbor/v2"
+
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/r1cs/r1c"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+	gnarkio "github.com/consensys/gnark/io"
 	"github.com/consensys/gurvy"
 )
 
@@ -40,7 +44,17 @@ type UntypedR1CS struct {
 	NbConstraints   uint64 // total number of constraints
 	NbCOConstraints uint64 // number of constraints that need to be solved, the first of the Constraints slice
 	Constraints     []r1c.R1C
-	Coefficients    []big.Int
+	Coefficients    []big.Int `cbor:"-"` // encoded separately (see WriteTo)
+
+	// Hints
+	Hints []r1c.Hint // wires computed by hint functions, not tied to a constraint
+
+	// Components
+	Components []r1c.Component // boundaries of the sub-circuits in the constraints
+
+	// Call stacks
+	CallStacks   [][]string // unique call stacks of the constraints
+	CallStackIDs []int      // index in CallStacks of the call stack of each constraint (-1 if none), empty if no stack was recorded
 }
 
 // GetNbConstraints returns the number of constraints
@@ -58,9 +72,38 @@ func (r1cs *UntypedR1CS) GetNbCoefficients() int {
 	return len(r1cs.Coefficients)
 }
 
-// WriteTo panics (can't serialize untyped R1CS)
-func (r1cs *UntypedR1CS) WriteTo(w io.Writer) (n int64, err error) {
-	panic("not implemented: can't serialize untyped R1CS")
+// GetComponents returns the sub-circuits boundaries in the constraints
+func (r1cs *UntypedR1CS) GetComponents() []r1c.Component {
+	return r1cs.Components
+}
+
+// GetInputNames returns the names of the secret and public inputs, ordered as the wires
+// (the ONE wire is the first public input)
+func (r1cs *UntypedR1CS) GetInputNames() (secret, public []string) {
+	return r1cs.SecretWires, r1cs.PublicWires
+}
+
+// WriteTo encodes the UntypedR1CS into provided io.Writer using cbor, in a gnark container
+// (see gnarkio.WriteContainer)
+func (r1cs *UntypedR1CS) WriteTo(w io.Writer) (int64, error) {
+	return gnarkio.WriteContainer(w, gurvy.UNKNOWN, gnarkio.ObjectR1CS, func(w io.Writer) (int64, error) {
+		_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+		encoder := cbor.NewEncoder(&_w)
+
+		// cbor can't encode big.Int values, the coefficients follow the R1CS in gob encoding
+		if err := encoder.Encode(r1cs); err != nil {
+			return _w.N, err
+		}
+		coefficients := make([][]byte, len(r1cs.Coefficients))
+		for i := 0; i < len(r1cs.Coefficients); i++ {
+			var err error
+			if coefficients[i], err = r1cs.Coefficients[i].GobEncode(); err != nil {
+				return _w.N, err
+			}
+		}
+		err := encoder.Encode(coefficients)
+		return _w.N, err
+	})
 }
 
 // GetCurveID returns gurvy.UNKNOWN as this is a untyped R1CS using big.Int
@@ -68,14 +111,66 @@ func (r1cs *UntypedR1CS) GetCurveID() gurvy.ID {
 	return gurvy.UNKNOWN
 }
 
-// ReadFrom panics (can't deserialize untyped R1CS)
-func (r1cs *UntypedR1CS) ReadFrom(r io.Reader) (n int64, err error) {
-	panic("not implemented: can't deserialize untyped R1CS")
+// ReadFrom attempts to decode the UntypedR1CS from io.Reader using cbor
+// the data must be a gnark container holding an untyped R1CS (see gnarkio.ReadContainer)
+func (r1cs *UntypedR1CS) ReadFrom(r io.Reader) (int64, error) {
+	return gnarkio.ReadContainer(r, gurvy.UNKNOWN, gnarkio.ObjectR1CS, func(r io.Reader) (int64, error) {
+		decoder := cbor.NewDecoder(r)
+
+		if err := decoder.Decode(r1cs); err != nil {
+			return int64(decoder.NumBytesRead()), err
+		}
+		var coefficients [][]byte
+		if err := decoder.Decode(&coefficients); err != nil {
+			return int64(decoder.NumBytesRead()), err
+		}
+		r1cs.Coefficients = make([]big.Int, len(coefficients))
+		for i := 0; i < len(coefficients); i++ {
+			if err := r1cs.Coefficients[i].GobDecode(coefficients[i]); err != nil {
+				return int64(decoder.NumBytesRead()), err
+			}
+		}
+		return int64(decoder.NumBytesRead()), nil
+	})
 }
 
-// IsSolved call will panic as we can't solve a UntypedR1CS
+// IsSolved returns ErrNoModulus: an UntypedR1CS is solved modulo a prime (see IsSolvedModulo)
 func (r1cs *UntypedR1CS) IsSolved(solution map[string]interface{}) error {
-	panic("not implemented")
+	return ErrNoModulus
+}
+
+// Solution returns ErrNoModulus: an UntypedR1CS is solved modulo a prime (see SolutionModulo)
+func (r1cs *UntypedR1CS) Solution(assignment map[string]interface{}) (*backend.Solution, error) {
+	return nil, ErrNoModulus
+}
+
+// IsSolvedModulo returns nil if the assignment solves the R1CS modulo the given prime, and an error otherwise
+func (r1cs *UntypedR1CS) IsSolvedModulo(modulus *big.Int, assignment map[string]interface{}) error {
+	_, err := r1cs.SolutionModulo(modulus, assignment)
+	return err
+}
+
+// SolutionModulo solves the R1CS modulo the given prime and returns the values of all its wires,
+// internal wires included
+//
+// the R1CS can then be solved over fields with no generated backend
+func (r1cs *UntypedR1CS) SolutionModulo(modulus *big.Int, assignment map[string]interface{}) (*backend.Solution, error) {
+	s, err := newSolver(r1cs, modulus)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.instantiateInputs(assignment); err != nil {
+		return nil, err
+	}
+	if err := s.solve(); err != nil {
+		return nil, err
+	}
+
+	solution := backend.NewSolution(int(r1cs.NbWires-r1cs.NbPublicWires-r1cs.NbSecretWires), r1cs.SecretWires, r1cs.PublicWires)
+	for i := 0; i < len(s.values); i++ {
+		solution.Values[i].Set(&s.values[i])
+	}
+	return solution, nil
 }
 
 // ToR1CS will convert the big.Int coefficients in the UntypedR1CS to field elements
@@ -0,0 +1,130 @@
+package r1cs_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type untypedCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *untypedCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	q, r := cs.DivMod(c.X, c.Y, 8)
+	cs.ToBinary(cs.Mul(q, r), 8)
+	cs.AssertIsEqual(cs.Mul(cs.Div(c.X, c.Y), c.Y), c.X)
+	cs.AssertIsEqual(cs.Add(cs.IsZero(r), q), c.Z)
+	return nil
+}
+
+func TestUntypedR1CS(t *testing.T) {
+	var circuit untypedCircuit
+	res, err := frontend.Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untyped := res.(*r1cs.UntypedR1CS)
+
+	// serialization round trip
+	var buf bytes.Buffer
+	written, err := untyped.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reconstructed r1cs.UntypedR1CS
+	read, err := reconstructed.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != read || !reflect.DeepEqual(untyped, &reconstructed) {
+		t.Fatal("round trip serialization failed")
+	}
+
+	if err := untyped.IsSolved(map[string]interface{}{}); !errors.Is(err, r1cs.ErrNoModulus) {
+		t.Fatal("expected ErrNoModulus, got", err)
+	}
+
+	// solving modulo the scalar field of a curve matches the typed solver
+	typed := untyped.ToR1CS(gurvy.BN256)
+	modulus := r1cs.FieldModulus(gurvy.BN256)
+	good := map[string]interface{}{"X": 42, "Y": 8, "Z": 5}
+	expected, err := typed.Solution(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution, err := untyped.SolutionModulo(modulus, good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected.Names, solution.Names) || len(expected.Values) != len(solution.Values) {
+		t.Fatal("unexpected wires")
+	}
+	for i := 0; i < len(expected.Values); i++ {
+		if expected.Values[i].Cmp(&solution.Values[i]) != 0 {
+			t.Fatal("unexpected value of wire", i)
+		}
+	}
+
+	// 42 = 5*8 + 2, so Z must be 5
+	bad := map[string]interface{}{"X": 42, "Y": 8, "Z": 6}
+	var expectedErr, unsatisfied *backend.UnsatisfiedConstraintError
+	if !errors.As(typed.IsSolved(bad), &expectedErr) || !errors.As(untyped.IsSolvedModulo(modulus, bad), &unsatisfied) {
+		t.Fatal("expected unsatisfied constraint errors")
+	}
+	if !reflect.DeepEqual(expectedErr, unsatisfied) {
+		t.Fatal("unexpected error", unsatisfied, "expected", expectedErr)
+	}
+
+	// a field with no generated backend
+	small := big.NewInt(65537)
+	if err := untyped.IsSolvedModulo(small, good); err != nil {
+		t.Fatal(err)
+	}
+	if err := untyped.IsSolvedModulo(small, bad); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error, got", err)
+	}
+	if err := untyped.IsSolvedModulo(big.NewInt(65536), good); err == nil {
+		t.Fatal("expected an error for a modulus which is not prime")
+	}
+}
+
+type binaryDecCircuit struct {
+	X frontend.Variable
+	B frontend.Variable `gnark:",public"`
+}
+
+func (c *binaryDecCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	bits := cs.ToBinary(c.X, 33)
+	cs.AssertIsEqual(bits[0], c.B)
+	cs.AssertIsEqual(bits[32], 0)
+	return nil
+}
+
+func TestUntypedBinaryDecSmallField(t *testing.T) {
+	var circuit binaryDecCircuit
+	res, err := frontend.Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untyped := res.(*r1cs.UntypedR1CS)
+
+	// 2^32 = 1 mod 65537, the 33 bits must still be read from their unreduced coefficients
+	small := big.NewInt(65537)
+	for _, x := range []int{0, 1, 3, 65536} {
+		if err := untyped.IsSolvedModulo(small, map[string]interface{}{"X": x, "B": x & 1}); err != nil {
+			t.Fatal(x, err)
+		}
+	}
+	if err := untyped.IsSolvedModulo(small, map[string]interface{}{"X": 3, "B": 0}); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error, got", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import "math/big"
+
+// Solution is a solved witness of a R1CS: the values of all its wires, internal wires included
+//
+// wires are ordered as in the R1CS: [internal wires | secret inputs | public inputs], the ONE wire
+// being the first public input
+type Solution struct {
+	NbInternalWires int
+	NbSecretWires   int
+	NbPublicWires   int
+	Names           []string  // name of each wire, empty for the internal wires
+	Values          []big.Int // value of each wire, in regular form
+}
+
+// NewSolution returns a Solution of a R1CS with the given wires, the values are set by the caller
+func NewSolution(nbInternalWires int, secretNames, publicNames []string) *Solution {
+	nbWires := nbInternalWires + len(secretNames) + len(publicNames)
+	s := &Solution{
+		NbInternalWires: nbInternalWires,
+		NbSecretWires:   len(secretNames),
+		NbPublicWires:   len(publicNames),
+		Names:           make([]string, nbWires),
+		Values:          make([]big.Int, nbWires),
+	}
+	copy(s.Names[nbInternalWires:], secretNames)
+	copy(s.Names[nbInternalWires+len(secretNames):], publicNames)
+	return s
+}
+
+// Value returns the value of the input wire with the given name, and false if there is none
+func (s *Solution) Value(name string) (*big.Int, bool) {
+	for i := s.NbInternalWires; i < len(s.Names); i++ {
+		if s.Names[i] == name {
+			return &s.Values[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package witness provides curve-typed assignments of the inputs of a R1CS,
+// with a compact binary serialization
+package witness
+
+import (
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/frontend"
+	backend_bls377 "github.com/consensys/gnark/internal/backend/bls377"
+	backend_bls381 "github.com/consensys/gnark/internal/backend/bls381"
+	backend_bn256 "github.com/consensys/gnark/internal/backend/bn256"
+	backend_bw761 "github.com/consensys/gnark/internal/backend/bw761"
+	"github.com/consensys/gurvy"
+)
+
+// Witness is an assignment of the inputs of a R1CS, secret inputs first, then public inputs,
+// ordered as the R1CS wires. A public witness (see Public) holds no secret values
+//
+// it's underlying implementation is curve specific (see gnark/internal/backend)
+type Witness interface {
+	io.WriterTo
+	io.ReaderFrom
+	GetCurveID() gurvy.ID
+	IsPublic() bool
+	Assignment(secretNames, publicNames []string) (map[string]interface{}, error)
+}
+
+// ErrSecretInPublicWitness is returned when reading a public witness that holds secret values,
+// or when verifying a proof with a witness that isn't public (see groth16.Verify)
+var ErrSecretInPublicWitness = backend.ErrSecretInPublicWitness
+
+// Full returns the Witness of the inputs of the R1CS from assignment
+//
+// assignment must be map[string]interface{} or must implement frontend.Circuit
+// ( see frontend.ParseWitness )
+func Full(r1cs r1cs.R1CS, assignment interface{}) (Witness, error) {
+	_assignment, err := frontend.ParseWitness(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	var w Witness
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls377.R1CS:
+		_w := &backend_bls377.Witness{}
+		err, w = _w.FromAssignment(_r1cs, _assignment), _w
+	case *backend_bls381.R1CS:
+		_w := &backend_bls381.Witness{}
+		err, w = _w.FromAssignment(_r1cs, _assignment), _w
+	case *backend_bn256.R1CS:
+		_w := &backend_bn256.Witness{}
+		err, w = _w.FromAssignment(_r1cs, _assignment), _w
+	case *backend_bw761.R1CS:
+		_w := &backend_bw761.Witness{}
+		err, w = _w.FromAssignment(_r1cs, _assignment), _w
+	default:
+		return nil, errors.New("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Public returns the public part of the Witness of the inputs of the R1CS from assignment,
+// ordered as the VerifyingKey.PublicInputs, to be given to a verifier. The secret values of
+// assignment, if any, are left out
+//
+// assignment must be map[string]interface{} or must implement frontend.Circuit
+// ( see frontend.ParseWitness )
+func Public(r1cs r1cs.R1CS, assignment interface{}) (Witness, error) {
+	_assignment, err := frontend.ParseWitness(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	var w Witness
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls377.R1CS:
+		_w := &backend_bls377.Witness{}
+		err, w = _w.FromPublicAssignment(_r1cs, _assignment), _w
+	case *backend_bls381.R1CS:
+		_w := &backend_bls381.Witness{}
+		err, w = _w.FromPublicAssignment(_r1cs, _assignment), _w
+	case *backend_bn256.R1CS:
+		_w := &backend_bn256.Witness{}
+		err, w = _w.FromPublicAssignment(_r1cs, _assignment), _w
+	case *backend_bw761.R1CS:
+		_w := &backend_bw761.Witness{}
+		err, w = _w.FromPublicAssignment(_r1cs, _assignment), _w
+	default:
+		return nil, errors.New("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// New instantiates a curve-typed Witness and returns an interface object
+// This function exists for serialization purposes
+func New(curveID gurvy.ID) Witness {
+	var w Witness
+	switch curveID {
+	case gurvy.BN256:
+		w = &backend_bn256.Witness{}
+	case gurvy.BLS377:
+		w = &backend_bls377.Witness{}
+	case gurvy.BLS381:
+		w = &backend_bls381.Witness{}
+	case gurvy.BW761:
+		w = &backend_bw761.Witness{}
+	default:
+		panic("not implemented")
+	}
+	return w
+}
+
+// ReadPublic decodes a public witness (see Public) from reader
+//
+// it returns ErrSecretInPublicWitness if the encoded witness holds secret values
+func ReadPublic(reader io.Reader, curveID gurvy.ID) (Witness, error) {
+	w := New(curveID)
+	if _, err := w.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	if !w.IsPublic() {
+		return nil, ErrSecretInPublicWitness
+	}
+	return w, nil
+}
+
+// ReadInto decodes a full witness (see Full) from reader and assigns the inputs of circuit with it
+// (see frontend.AssignWitness); the values are matched to the inputs by their order, as Compile
+// allocates the wires
+func ReadInto(reader io.Reader, curveID gurvy.ID, circuit frontend.Circuit) error {
+	w := New(curveID)
+	if _, err := w.ReadFrom(reader); err != nil {
+		return err
+	}
+	secretNames, publicNames, err := frontend.InputNames(circuit)
+	if err != nil {
+		return err
+	}
+	assignment, err := w.Assignment(secretNames, publicNames)
+	if err != nil {
+		return err
+	}
+	return frontend.AssignWitness(circuit, assignment, curveID)
+}
@@ -0,0 +1,74 @@
+package witness
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type circuit struct {
+	X [2]frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *circuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	cs.AssertIsEqual(cs.Mul(c.X[0], c.X[1]), c.Y)
+	return nil
+}
+
+func TestReadInto(t *testing.T) {
+	for _, curveID := range []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761} {
+		var c circuit
+		r1cs, err := frontend.Compile(curveID, &c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var assignment circuit
+		assignment.X[0].Assign(3)
+		assignment.X[1].Assign(5)
+		assignment.Y.Assign(15)
+		w, err := Full(r1cs, &assignment)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := w.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+
+		var decoded circuit
+		if err := ReadInto(bytes.NewReader(encoded), curveID, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if err := r1cs.IsSolved(mustParse(t, &decoded)); err != nil {
+			t.Fatal(err)
+		}
+
+		// a public witness lacks the secret inputs
+		pw, err := Public(r1cs, &assignment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if _, err := pw.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadInto(&buf, curveID, &circuit{}); err == nil {
+			t.Fatal("expected an error when reading a public witness into a circuit with secret inputs")
+		}
+	}
+}
+
+func mustParse(t *testing.T, c frontend.Circuit) map[string]interface{} {
+	t.Helper()
+	res, err := frontend.ParseWitness(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
@@ -2,10 +2,12 @@ package frontend
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
 	"github.com/consensys/gurvy"
 )
 
@@ -28,10 +30,24 @@ type Circuit interface {
 // from the declarative code
 //
 // 3. finally, it converts that to a R1CS
-func Compile(curveID gurvy.ID, circuit Circuit) (r1cs.R1CS, error) {
+//
+// the compilation can be tuned with options (see CompileOption); by default the R1CS is not optimized,
+// keeps the debug info and is typed for curveID (or untyped if curveID is gurvy.UNKNOWN)
+func Compile(curveID gurvy.ID, circuit Circuit, opts ...CompileOption) (r1cs.R1CS, error) {
+
+	config := compileConfig{
+		debugInfo: true,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
 
 	// instantiate our constraint system
-	cs := newConstraintSystem()
+	cs := newConstraintSystem(config.capacity)
+	cs.curveID = curveID
+	cs.callStacks = config.callStacks
+	cs.strictParsing = config.strictParsing
+	cs.wideTerms = config.wideTerms
 
 	// leaf handlers are called when encoutering leafs in the circuit data struct
 	// leafs are Constraints that need to be initialized in the context of compiling a circuit
@@ -58,7 +74,11 @@ func Compile(curveID gurvy.ID, circuit Circuit) (r1cs.R1CS, error) {
 
 	// recursively parse through reflection the circuits members to find all Constraints that need to be allOoutputcated
 	// (secret or public inputs)
-	if err := parseType(circuit, "", backend.Unset, handler); err != nil {
+	parse := parseType
+	if config.strictParsing {
+		parse = parseTypeStrict
+	}
+	if err := parse(circuit, "", backend.Unset, handler); err != nil {
 		return nil, err
 	}
 
@@ -66,22 +86,143 @@ func Compile(curveID gurvy.ID, circuit Circuit) (r1cs.R1CS, error) {
 	if err := circuit.Define(curveID, &cs); err != nil {
 		return nil, err
 	}
-	// return R1CS
-	//return cs.toR1CS(curveID), nil
-	res, err := cs.toR1CS(curveID)
+	cs.flushRangeChecks()
+
+	// the wire and coefficient IDs must fit in the terms of the R1CS
+	capacityErr := func(err error) error {
+		if err != nil && !config.wideTerms {
+			return fmt.Errorf("%w (see WithWideTerms)", err)
+		}
+		return err
+	}
+	if cs.termErr != nil {
+		return nil, capacityErr(cs.termErr)
+	}
+	nbWires := len(cs.internal.variables) + len(cs.secret.variables) + len(cs.public.variables)
+	if err := capacityErr(r1c.CheckCapacity(nbWires, len(cs.coeffs), config.wideTerms)); err != nil {
+		return nil, err
+	}
+
+	res, err := cs.toR1CS()
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	// coefficients can only be reduced once we know the field
+	if config.optimize && curveID != gurvy.UNKNOWN {
+		res.Optimize(curveID)
+
+		// the optimizer adds coefficients
+		if err := capacityErr(r1c.CheckCapacity(int(res.NbWires), len(res.Coefficients), config.wideTerms)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !config.debugInfo {
+		res.DebugInfo = nil
+		res.Logs = nil
+		res.CallStacks = nil
+		res.CallStackIDs = nil
+	}
+
+	if config.untyped || curveID == gurvy.UNKNOWN {
+		return res, nil
+	}
+
+	return res.ToR1CS(curveID), nil
+}
+
+// CompileOption tunes the behavior of Compile
+type CompileOption func(config *compileConfig)
+
+type compileConfig struct {
+	capacity      int
+	optimize      bool
+	debugInfo     bool
+	callStacks    bool
+	untyped       bool
+	strictParsing bool
+	wideTerms     bool
+}
+
+// WithCapacity pre-allocates room for capacity constraints and internal variables;
+// on large circuits, a good estimate noticeably speeds up the compilation
+func WithCapacity(capacity int) CompileOption {
+	return func(config *compileConfig) {
+		config.capacity = capacity
+	}
+}
+
+// WithOptimizations enables or disables the optimization pass (see r1cs.UntypedR1CS.Optimize)
+// run on the R1CS when the curve is known; it is disabled by default, as it changes the R1CS
+// (and hence the proving and verifying keys) of existing circuits
+func WithOptimizations(enabled bool) CompileOption {
+	return func(config *compileConfig) {
+		config.optimize = enabled
+	}
+}
+
+// WithoutDebugInfo strips the debug info and call stacks attached to the assertions and the logs
+// recorded with ConstraintSystem.Println, for production builds.
+// A failing assertion is then only reported by its index
+func WithoutDebugInfo() CompileOption {
+	return func(config *compileConfig) {
+		config.debugInfo = false
+	}
+}
+
+// WithCallStacks records the call stack of each computational constraint and assertion
+// (most assertions of the API record theirs anyway), so that the solver can report where
+// a failing constraint was added (see backend.UnsatisfiedConstraintError). It slows down the compilation of large circuits
+func WithCallStacks() CompileOption {
+	return func(config *compileConfig) {
+		config.callStacks = true
+	}
+}
+
+// WithUntypedR1CS makes Compile return a *r1cs.UntypedR1CS even when the curve is known.
+// If optimizations are enabled, its coefficients are then reduced modulo the curve's scalar field
+func WithUntypedR1CS() CompileOption {
+	return func(config *compileConfig) {
+		config.untyped = true
+	}
+}
+
+// WithStrictParsing makes Compile return an error when it finds a Variable in the circuit struct it
+// can't allocate (for example, an unexported field, a nil pointer or an empty slice) instead of
+// ignoring it with a warning (see Logger)
+func WithStrictParsing() CompileOption {
+	return func(config *compileConfig) {
+		config.strictParsing = true
+	}
+}
+
+// WithWideTerms allows the R1CS to use wide terms (see r1c.Term), for circuits with more than
+// r1c.MaxWires wires, at the cost of fewer distinct coefficients (r1c.MaxWideCoefficients)
+func WithWideTerms() CompileOption {
+	return func(config *compileConfig) {
+		config.wideTerms = true
+	}
 }
 
 // ParseWitness will returns a map[string]interface{} to be used as input in
 // in R1CS.Solve(), groth16.Prove()
 //
 // if input is not already a map[string]interface{}, it must implement frontend.Circuit
+//
+// the Variables ParseWitness can't reach are ignored with a warning (see Logger), and those which are not
+// assigned are left out of the map
 func ParseWitness(input interface{}) (map[string]interface{}, error) {
+	return parseWitness(input, false)
+}
 
+// ParseWitnessStrict is like ParseWitness, but returns an error instead of ignoring a Variable it can't
+// reach (see WithStrictParsing) or which is not assigned
+func ParseWitnessStrict(input interface{}) (map[string]interface{}, error) {
+	return parseWitness(input, true)
+}
+
+func parseWitness(input interface{}, strict bool) (map[string]interface{}, error) {
 	switch c := input.(type) {
 	case map[string]interface{}:
 		return c, nil
@@ -94,6 +235,8 @@ func ParseWitness(input interface{}) (map[string]interface{}, error) {
 
 			if v.val != nil {
 				toReturn[name] = v.val
+			} else if strict {
+				return fmt.Errorf("%q: %w", name, backend.ErrInputNotSet)
 			}
 
 			return nil
@@ -101,6 +244,9 @@ func ParseWitness(input interface{}) (map[string]interface{}, error) {
 
 		// recursively parse through reflection the circuits members to find all inputs that need to be allOoutputcated
 		// (secret or public inputs)
+		if strict {
+			return toReturn, parseTypeStrict(c, "", backend.Unset, extractHandler)
+		}
 		return toReturn, parseType(c, "", backend.Unset, extractHandler)
 	default:
 		rValue := reflect.ValueOf(input)
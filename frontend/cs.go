@@ -22,10 +22,12 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/backend/r1cs"
 	"github.com/consensys/gnark/backend/r1cs/r1c"
 	"github.com/consensys/gurvy"
@@ -63,10 +65,32 @@ type ConstraintSystem struct {
 	coeffs    []big.Int      // list of unique coefficients.
 	coeffsIDs map[string]int // map to fast check existence of a coefficient (key = coeff.Text(16))
 
+	// Hints
+	hints []r1c.Hint // list of wires computed by a hint function (see NewHint)
+
+	// Components
+	curveID        gurvy.ID        // curve the circuit is compiled for, given to the components Define
+	components     []r1c.Component // sub-circuits boundaries in constraints and assertions (see Define)
+	componentStack []int           // components being defined, the innermost one is the last
+	strictParsing  bool            // if set, the components are parsed with parseTypeStrict (see WithStrictParsing)
+
+	// Terms
+	wideTerms bool  // if set, the terms whose wire ID doesn't fit are wide terms (see WithWideTerms)
+	termErr   error // first wire or coefficient ID which didn't fit in a term, returned by Compile
+
+	// Range checks
+	rangeChecks       []rangeCheck          // range checks deferred until the circuit is compiled (see RangeCheck)
+	bitDecompositions map[string][]Variable // binary decompositions computed by ToBinary (key = linExpKey)
+
 	// debug info
 	logs           []logEntry // list of logs to be printed when solving a circuit. The logs are called with the method Println
 	debugInfo      []logEntry // list of logs storing information about assertions. If an assertion fails, it prints it in a friendly format
 	unsetVariables []logEntry // unset variables. If a variable is unset, the error is caught when compiling the circuit
+	callStacks     bool       // if set, the call stack of each computational constraint is recorded (see WithCallStacks)
+	coStacks       [][]string // call stacks of the computational constraints, if recorded
+
+	// test engine
+	engine *engine // if set, the constraints are executed on concrete values as they are added (see IsSolved)
 
 }
 
@@ -74,16 +98,17 @@ func (cs *ConstraintSystem) buildVarFromPartialVar(pv Wire) Variable {
 	return Variable{pv, cs.LinearExpression(cs.makeTerm(pv, bOne)), false}
 }
 
-// this has quite some impact on frontend performance, especially on large circuits size
-// we may want to add build tags to tune that
-const initialCapacity = 1e6
-
-func newConstraintSystem() ConstraintSystem {
+// newConstraintSystem returns a constraint system with room for capacity constraints and
+// internal variables; this has quite some impact on frontend performance, especially on
+// large circuits size (see WithCapacity)
+func newConstraintSystem(capacity int) ConstraintSystem {
 	cs := ConstraintSystem{
 		coeffs:      make([]big.Int, 0),
 		coeffsIDs:   make(map[string]int),
-		constraints: make([]r1c.R1C, 0, initialCapacity),
+		constraints: make([]r1c.R1C, 0, capacity),
 		assertions:  make([]r1c.R1C, 0),
+
+		bitDecompositions: make(map[string][]Variable),
 	}
 
 	cs.public.names = make([]string, 0)
@@ -94,7 +119,7 @@ func newConstraintSystem() ConstraintSystem {
 	cs.secret.variables = make([]Variable, 0)
 	cs.secret.booleans = make(map[int]struct{})
 
-	cs.internal.variables = make([]Variable, 0, initialCapacity)
+	cs.internal.variables = make([]Variable, 0, capacity)
 	cs.internal.booleans = make(map[int]struct{})
 
 	// by default the circuit is given on public wire equal to 1
@@ -103,9 +128,128 @@ func newConstraintSystem() ConstraintSystem {
 	return cs
 }
 
+// rangeCheck is a deferred assertion (0 <= v < 2^nbBits)
+type rangeCheck struct {
+	v         Variable
+	nbBits    int
+	debugInfo logEntry
+}
+
+// linExpKey returns a key identifying the linear expression, regardless of the order of its terms
+func linExpKey(linExp r1c.LinearExpression) string {
+	terms := make([]uint64, len(linExp))
+	for i := 0; i < len(linExp); i++ {
+		terms[i] = uint64(linExp[i])
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i] < terms[j] })
+	return fmt.Sprint(terms)
+}
+
+// flushRangeChecks adds the assertions of the range checks recorded with RangeCheck
+// (in the current component, see Define)
+func (cs *ConstraintSystem) flushRangeChecks() {
+
+	// keep the smallest width requested for each variable, in the order of the first request
+	keys := make([]string, 0, len(cs.rangeChecks))
+	checks := make(map[string]rangeCheck, len(cs.rangeChecks))
+	for _, rc := range cs.rangeChecks {
+		key := linExpKey(rc.v.linExp)
+		prev, ok := checks[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+		if !ok || rc.nbBits < prev.nbBits {
+			checks[key] = rc
+		}
+	}
+	cs.rangeChecks = nil
+
+	for _, key := range keys {
+		rc := checks[key]
+
+		// v = sum(b[i]*2^i) is known from a previous decomposition: the bits above nbBits must be 0.
+		// It costs len(b)-nbBits assertions (nothing if len(b) <= nbBits)
+		if b, ok := cs.bitDecompositions[key]; ok && len(b)-rc.nbBits <= rc.nbBits {
+			for i := rc.nbBits; i < len(b); i++ {
+				o := cs.Constant(0)      // no constraint is recorded
+				r := cs.getOneVariable() // no constraint is recorded
+				constraint := r1c.R1C{L: b[i].getLinExpCopy(), R: r.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
+				cs.addAssertion(constraint, rc.debugInfo)
+			}
+			continue
+		}
+
+		// the nbBits-1 low bits are computed by the solver and checked with assertions only,
+		// so that a value out of range fails with rc.debugInfo
+		bits := make([]Variable, rc.nbBits)
+		var coeff big.Int
+		coeff.Set(bOne)
+		low := cs.Constant(0) // no constraint is recorded
+		for i := 0; i < rc.nbBits-1; i++ {
+			bits[i] = cs.NewHint(hint.IthBit, rc.v, i)
+			cs.assertIsBoolean(bits[i], rc.debugInfo)
+			bits[i].isBoolean = true
+			low = cs.Add(low, cs.Mul(coeff, bits[i])) // no constraint is recorded
+			coeff.Lsh(&coeff, 1)
+		}
+
+		// the most significant bit is not a wire: top = v - low = 2^(nbBits-1) * msb must be 0 or 2^(nbBits-1),
+		// which also checks the decomposition. It costs nbBits assertions
+		top := cs.Sub(rc.v, low)             // no constraint is recorded
+		r := cs.Sub(cs.Constant(coeff), top) // no constraint is recorded
+		o := cs.Constant(0)                  // no constraint is recorded
+		constraint := r1c.R1C{L: top.getLinExpCopy(), R: r.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
+		cs.addAssertion(constraint, rc.debugInfo)
+
+		// top is the most significant bit up to a factor, it is only compared to 0 when the decomposition is reused
+		bits[rc.nbBits-1] = top
+		cs.bitDecompositions[key] = bits
+	}
+}
+
+// maxNbBits returns the largest number of bits of the integers whose binary decomposition is unique
+// in the scalar field of the curve (the bit length of the modulus, minus 1). If the curve is not known,
+// it is the smallest one of the supported curves
+func (cs *ConstraintSystem) maxNbBits() int {
+	if cs.curveID != gurvy.UNKNOWN {
+		return r1cs.FieldModulus(cs.curveID).BitLen() - 1
+	}
+	res := -1
+	for _, curveID := range []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761} {
+		if n := r1cs.FieldModulus(curveID).BitLen() - 1; res == -1 || n < res {
+			res = n
+		}
+	}
+	return res
+}
+
+// toBinaryHint decomposes v in nbBits bits (little endian) like ToBinary, but the bits are computed
+// by the solver (see hint.IthBit) and checked with assertions only, so that a value which doesn't
+// fit in nbBits bits fails with debugInfo. It costs nbBits+1 assertions
+func (cs *ConstraintSystem) toBinaryHint(v Variable, nbBits int, debugInfo logEntry) []Variable {
+	bits := make([]Variable, nbBits)
+	var coeff big.Int
+	coeff.Set(bOne)
+	sum := cs.Constant(0) // no constraint is recorded
+	for i := 0; i < nbBits; i++ {
+		bits[i] = cs.NewHint(hint.IthBit, v, i)
+		cs.assertIsBoolean(bits[i], debugInfo)
+		bits[i].isBoolean = true
+		sum = cs.Add(sum, cs.Mul(coeff, bits[i])) // no constraint is recorded
+		coeff.Lsh(&coeff, 1)
+	}
+
+	r := cs.getOneVariable() // no constraint is recorded
+	constraint := r1c.R1C{L: sum.getLinExpCopy(), R: r.getLinExpCopy(), O: v.getLinExpCopy(), Solver: r1c.SingleOutput}
+	cs.addAssertion(constraint, debugInfo)
+
+	return bits
+}
+
 type logEntry struct {
 	format    string
 	toResolve []r1c.Term
+	stack     []string // call stack of the assertion the entry describes, if any
 }
 
 var (
@@ -143,7 +287,7 @@ func (cs *ConstraintSystem) getOneVariable() Variable {
 // Term packs a variable and a coeff in a r1c.Term and returns it.
 func (cs *ConstraintSystem) makeTerm(v Wire, coeff *big.Int) r1c.Term {
 
-	term := r1c.Pack(v.id, cs.coeffID(coeff), v.visibility)
+	term := cs.packTerm(v.id, cs.coeffID(coeff), v.visibility)
 
 	if coeff.Cmp(bZero) == 0 {
 		term.SetCoeffValue(0)
@@ -157,6 +301,22 @@ func (cs *ConstraintSystem) makeTerm(v Wire, coeff *big.Int) r1c.Term {
 	return term
 }
 
+// packTerm packs a wire ID and a coefficient ID in a r1c.Term, a wide one if the wire ID needs it and
+// cs.wideTerms is set. If they don't fit, the error is recorded in cs.termErr (returned by Compile) and
+// the term is meaningless
+func (cs *ConstraintSystem) packTerm(wireID, coeffID int, visibility backend.Visibility) r1c.Term {
+	if err := r1c.CheckCapacity(wireID+1, coeffID+1, cs.wideTerms); err != nil {
+		if cs.termErr == nil {
+			cs.termErr = err
+		}
+		return r1c.Pack(0, 0, visibility)
+	}
+	if wireID >= r1c.MaxWires {
+		return r1c.PackWide(wireID, coeffID, visibility)
+	}
+	return r1c.Pack(wireID, coeffID, visibility)
+}
+
 // NbConstraints enables circuit profiling and helps debugging
 // It returns the number of constraints created at the current stage of the circuit construction.
 //
@@ -255,13 +415,32 @@ func (cs *ConstraintSystem) bigIntValue(term r1c.Term) big.Int {
 	return coeff
 }
 
+func (cs *ConstraintSystem) addConstraint(constraint r1c.R1C) {
+	cs.constraints = append(cs.constraints, constraint)
+	if cs.callStacks {
+		cs.coStacks = append(cs.coStacks, getCallStack())
+	}
+	if cs.engine != nil {
+		if err := cs.executeConstraint(constraint); err != nil {
+			err.Stack = getCallStack()
+			cs.abort(err)
+		}
+	}
+}
+
 func (cs *ConstraintSystem) addAssertion(constraint r1c.R1C, debugInfo logEntry) {
+	if (cs.callStacks || cs.engine != nil) && len(debugInfo.stack) == 0 {
+		debugInfo.stack = getCallStack()
+	}
 	cs.assertions = append(cs.assertions, constraint)
 	cs.debugInfo = append(cs.debugInfo, debugInfo)
+	if cs.engine != nil {
+		cs.executeAssertion(constraint, debugInfo)
+	}
 }
 
 // toR1CS constructs a rank-1 constraint sytem
-func (cs *ConstraintSystem) toR1CS(curveID gurvy.ID) (r1cs.R1CS, error) {
+func (cs *ConstraintSystem) toR1CS() (*r1cs.UntypedR1CS, error) {
 
 	// wires = intermediatevariables | secret inputs | public inputs
 
@@ -278,6 +457,7 @@ func (cs *ConstraintSystem) toR1CS(curveID gurvy.ID) (r1cs.R1CS, error) {
 		Coefficients:    cs.coeffs,
 		Logs:            make([]backend.LogEntry, len(cs.logs)),
 		DebugInfo:       make([]backend.LogEntry, len(cs.debugInfo)),
+		Hints:           make([]r1c.Hint, len(cs.hints)),
 	}
 
 	// computational constraints (= gates)
@@ -290,12 +470,19 @@ func (cs *ConstraintSystem) toR1CS(curveID gurvy.ID) (r1cs.R1CS, error) {
 			_, _, cID, cVisibility := exp[j].Unpack()
 			switch cVisibility {
 			case backend.Public:
-				exp[j].SetVariableID(cID + len(cs.internal.variables) + len(cs.secret.variables))
+				cID += len(cs.internal.variables) + len(cs.secret.variables)
 			case backend.Secret:
-				exp[j].SetVariableID(cID + len(cs.internal.variables))
+				cID += len(cs.internal.variables)
 			case backend.Unset:
 				return fmt.Errorf("%w: %s", backend.ErrInputNotSet, cs.unsetVariables[0].format)
+			default:
+				continue
 			}
+			// the capacity was checked by Compile
+			if cs.wideTerms && cID >= r1c.MaxWires {
+				exp[j].Widen()
+			}
+			exp[j].SetVariableID(cID)
 		}
 		return nil
 	}
@@ -316,6 +503,30 @@ func (cs *ConstraintSystem) toR1CS(curveID gurvy.ID) (r1cs.R1CS, error) {
 		}
 	}
 
+	// the assertions come after the computational constraints
+	res.Components = make([]r1c.Component, len(cs.components))
+	copy(res.Components, cs.components)
+	for i := 0; i < len(res.Components); i++ {
+		res.Components[i].Assertions[0] += len(cs.constraints)
+		res.Components[i].Assertions[1] += len(cs.constraints)
+	}
+
+	// hint outputs are internal wires (their ids need no offset), but their inputs do
+	for i := 0; i < len(cs.hints); i++ {
+		res.Hints[i] = r1c.Hint{
+			ID:     cs.hints[i].ID,
+			WireID: cs.hints[i].WireID,
+			Inputs: make([]r1c.LinearExpression, len(cs.hints[i].Inputs)),
+		}
+		for j := 0; j < len(cs.hints[i].Inputs); j++ {
+			res.Hints[i].Inputs[j] = make(r1c.LinearExpression, len(cs.hints[i].Inputs[j]))
+			copy(res.Hints[i].Inputs[j], cs.hints[i].Inputs[j])
+			if err := offsetIDs(res.Hints[i].Inputs[j]); err != nil {
+				return &res, err
+			}
+		}
+	}
+
 	// we need to offset the ids in logs too
 	for i := 0; i < len(cs.logs); i++ {
 		entry := backend.LogEntry{
@@ -358,11 +569,43 @@ func (cs *ConstraintSystem) toR1CS(curveID gurvy.ID) (r1cs.R1CS, error) {
 		res.DebugInfo[i] = entry
 	}
 
-	if curveID == gurvy.UNKNOWN {
-		return &res, nil
+	cs.setCallStacks(&res)
+
+	return &res, nil
+}
+
+// setCallStacks stores the recorded call stacks of the constraints in the R1CS;
+// identical stacks (for example, of constraints added in a loop) are stored once
+func (cs *ConstraintSystem) setCallStacks(res *r1cs.UntypedR1CS) {
+	stacks := make([][]string, 0, len(cs.coStacks)+len(cs.debugInfo))
+	stacks = append(stacks, cs.coStacks...)
+	for i := len(cs.coStacks); i < len(cs.constraints); i++ {
+		stacks = append(stacks, nil)
+	}
+	for i := 0; i < len(cs.debugInfo); i++ {
+		stacks = append(stacks, cs.debugInfo[i].stack)
 	}
 
-	return res.ToR1CS(curveID), nil
+	stackIDs := make(map[string]int)
+	for i := 0; i < len(stacks); i++ {
+		if len(stacks[i]) == 0 {
+			continue
+		}
+		if res.CallStackIDs == nil {
+			res.CallStackIDs = make([]int, len(stacks))
+			for j := 0; j < len(stacks); j++ {
+				res.CallStackIDs[j] = -1
+			}
+		}
+		key := strings.Join(stacks[i], "\n")
+		id, ok := stackIDs[key]
+		if !ok {
+			id = len(res.CallStacks)
+			stackIDs[key] = id
+			res.CallStacks = append(res.CallStacks, stacks[i])
+		}
+		res.CallStackIDs[i] = id
+	}
 }
 
 // coeffID tries to fetch the entry where b is if it exits, otherwise appends b to
@@ -392,7 +635,7 @@ func (cs *ConstraintSystem) allocate(v Variable) Variable {
 		iv := cs.newInternalVariable()
 		one := cs.getOneVariable()
 		constraint := r1c.R1C{L: v.getLinExpCopy(), R: one.getLinExpCopy(), O: iv.getLinExpCopy(), Solver: r1c.SingleOutput}
-		cs.constraints = append(cs.constraints, constraint)
+		cs.addConstraint(constraint)
 		return iv
 	}
 	return v
@@ -429,7 +672,7 @@ func (cs *ConstraintSystem) Println(a ...interface{}) {
 		// if the variable is only in linExp form, we allocate it
 		_v := cs.allocate(v)
 
-		entry.toResolve = append(entry.toResolve, r1c.Pack(_v.id, 0, _v.visibility))
+		entry.toResolve = append(entry.toResolve, cs.packTerm(_v.id, 0, _v.visibility))
 
 		if name == "" {
 			sbb.WriteString("%s")
@@ -456,6 +699,9 @@ func (cs *ConstraintSystem) Println(a ...interface{}) {
 	entry.format = sbb.String()
 
 	cs.logs = append(cs.logs, entry)
+	if cs.engine != nil {
+		fmt.Print(cs.resolve(entry))
+	}
 }
 
 // newInternalVariable creates a new wire, appends it on the list of wires of the circuit, sets
@@ -19,8 +19,11 @@ package frontend
 import (
 	"fmt"
 	"math/big"
+	"math/bits"
+	"reflect"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/backend/r1cs/r1c"
 )
 
@@ -119,7 +122,7 @@ func (cs *ConstraintSystem) Mul(i1, i2 interface{}, in ...interface{}) Variable
 				cs.completeDanglingVariable(&t2)
 				_res = cs.newInternalVariable() // only in this case we record the constraint in the cs
 				constraint := r1c.R1C{L: t1.getLinExpCopy(), R: t2.getLinExpCopy(), O: _res.getLinExpCopy(), Solver: r1c.SingleOutput}
-				cs.constraints = append(cs.constraints, constraint)
+				cs.addConstraint(constraint)
 				return _res
 			default:
 				_res = cs.mulConstant(t2, t1)
@@ -163,7 +166,7 @@ func (cs *ConstraintSystem) Inverse(v Variable) Variable {
 	R := res.linExp
 	O := cs.LinearExpression(cs.getOneTerm())
 	constraint := r1c.R1C{L: L, R: R, O: O, Solver: r1c.SingleOutput}
-	cs.constraints = append(cs.constraints, constraint)
+	cs.addConstraint(constraint)
 
 	return res
 }
@@ -182,11 +185,11 @@ func (cs *ConstraintSystem) Div(i1, i2 interface{}) Variable {
 		case Variable:
 			cs.completeDanglingVariable(&t2)
 			constraint := r1c.R1C{L: t2.linExp, R: res.linExp, O: t1.linExp, Solver: r1c.SingleOutput}
-			cs.constraints = append(cs.constraints, constraint)
+			cs.addConstraint(constraint)
 		default:
 			tmp := cs.Constant(t2)
 			constraint := r1c.R1C{L: tmp.getLinExpCopy(), R: res.getLinExpCopy(), O: t1.getLinExpCopy(), Solver: r1c.SingleOutput}
-			cs.constraints = append(cs.constraints, constraint)
+			cs.addConstraint(constraint)
 		}
 	default:
 		switch t2 := i2.(type) {
@@ -194,12 +197,12 @@ func (cs *ConstraintSystem) Div(i1, i2 interface{}) Variable {
 			cs.completeDanglingVariable(&t2)
 			tmp := cs.Constant(t1)
 			constraint := r1c.R1C{L: t2.getLinExpCopy(), R: res.getLinExpCopy(), O: tmp.getLinExpCopy(), Solver: r1c.SingleOutput}
-			cs.constraints = append(cs.constraints, constraint)
+			cs.addConstraint(constraint)
 		default:
 			tmp1 := cs.Constant(t1)
 			tmp2 := cs.Constant(t2)
 			constraint := r1c.R1C{L: tmp2.getLinExpCopy(), R: res.getLinExpCopy(), O: tmp1.getLinExpCopy(), Solver: r1c.SingleOutput}
-			cs.constraints = append(cs.constraints, constraint)
+			cs.addConstraint(constraint)
 		}
 	}
 
@@ -221,7 +224,7 @@ func (cs *ConstraintSystem) Xor(a, b Variable) Variable {
 	v2 = cs.Sub(v2, res) // no constraint recorded
 
 	constraint := r1c.R1C{L: v1.getLinExpCopy(), R: b.getLinExpCopy(), O: v2.getLinExpCopy(), Solver: r1c.SingleOutput}
-	cs.constraints = append(cs.constraints, constraint)
+	cs.addConstraint(constraint)
 
 	return res
 }
@@ -238,6 +241,7 @@ func (cs *ConstraintSystem) ToBinary(a Variable, nbBits int) []Variable {
 	for i := 0; i < nbBits; i++ {
 		res[i] = cs.newInternalVariable()
 		cs.AssertIsBoolean(res[i])
+		res[i].isBoolean = true
 	}
 
 	var coeff big.Int
@@ -256,7 +260,13 @@ func (cs *ConstraintSystem) ToBinary(a Variable, nbBits int) []Variable {
 	r := cs.getOneVariable()
 
 	constraint := r1c.R1C{L: v.getLinExpCopy(), R: r.getLinExpCopy(), O: a.getLinExpCopy(), Solver: r1c.BinaryDec}
-	cs.constraints = append(cs.constraints, constraint)
+	cs.addConstraint(constraint)
+
+	// keep the smallest decomposition of a, it may be reused by the range checks (see RangeCheck)
+	key := linExpKey(a.linExp)
+	if b, ok := cs.bitDecompositions[key]; !ok || nbBits < len(b) {
+		cs.bitDecompositions[key] = res
+	}
 
 	return res
 
@@ -309,7 +319,7 @@ func (cs *ConstraintSystem) Select(b Variable, i1, i2 interface{}) Variable {
 		w := cs.Sub(res, i2) // no constraint is recorded
 		//cs.Println("u-v: ", v)
 		constraint := r1c.R1C{L: b.getLinExpCopy(), R: v.getLinExpCopy(), O: w.getLinExpCopy(), Solver: r1c.SingleOutput}
-		cs.constraints = append(cs.constraints, constraint)
+		cs.addConstraint(constraint)
 		return res
 	default:
 		switch t2 := i2.(type) {
@@ -319,7 +329,7 @@ func (cs *ConstraintSystem) Select(b Variable, i1, i2 interface{}) Variable {
 			v := cs.Sub(t1, t2)  // no constraint is recorded
 			w := cs.Sub(res, t2) // no constraint is recorded
 			constraint := r1c.R1C{L: b.getLinExpCopy(), R: v.getLinExpCopy(), O: w.getLinExpCopy(), Solver: r1c.SingleOutput}
-			cs.constraints = append(cs.constraints, constraint)
+			cs.addConstraint(constraint)
 			return res
 		default:
 			// in this case, no constraint is recorded
@@ -333,6 +343,407 @@ func (cs *ConstraintSystem) Select(b Variable, i1, i2 interface{}) Variable {
 	}
 }
 
+// Lookup2 performs a 2-bit lookup between i0, i1, i2, i3 based on bits b0 and b1
+//
+// it returns i0 if b0 = b1 = 0, i1 if b0 = 1 and b1 = 0, i2 if b0 = 0 and b1 = 1, i3 if b0 = b1 = 1
+//
+// it costs 1 constraint if i0, i1, i2, i3 are constants, 3 otherwise
+func (cs *ConstraintSystem) Lookup2(b0, b1 Variable, i0, i1, i2, i3 interface{}) Variable {
+
+	cs.completeDanglingVariable(&b0)
+	cs.completeDanglingVariable(&b1)
+
+	// ensures that b0 and b1 are boolean
+	cs.AssertIsBoolean(b0)
+	cs.AssertIsBoolean(b1)
+
+	// res = i0 + b0*(i1-i0) + b1*(i2-i0) + b0*b1*(i3-i2-i1+i0)
+	_, v0 := i0.(Variable)
+	_, v1 := i1.(Variable)
+	_, v2 := i2.(Variable)
+	_, v3 := i3.(Variable)
+
+	if !(v0 || v1 || v2 || v3) {
+		// the table is constant, only b0*b1 needs to be computed
+		return cs.lookup2Constant(b0, b1, cs.Mul(b0, b1), i0, i1, i2, i3)
+	}
+
+	// b0*((i3-i2-i1+i0)*b1 + (i1-i0)) + b1*(i2-i0) + i0
+	tmp1 := cs.Sub(cs.Add(i3, i0), cs.Add(i2, i1))  // no constraint is recorded
+	tmp1 = cs.Add(cs.Mul(tmp1, b1), cs.Sub(i1, i0)) // 1 constraint
+	tmp1 = cs.Mul(tmp1, b0)                         // 1 constraint
+	tmp2 := cs.Mul(cs.Sub(i2, i0), b1)              // 1 constraint
+	return cs.Add(tmp1, tmp2, i0)                   // no constraint is recorded
+}
+
+// lookup2Constant returns the entry of the constant table i0, i1, i2, i3 selected by the bits b0 and b1
+// (see Lookup2), given b0b1 = b0*b1. No constraint is recorded
+func (cs *ConstraintSystem) lookup2Constant(b0, b1, b0b1 Variable, i0, i1, i2, i3 interface{}) Variable {
+	n0 := backend.FromInterface(i0)
+	n1 := backend.FromInterface(i1)
+	n2 := backend.FromInterface(i2)
+	n3 := backend.FromInterface(i3)
+
+	var c1, c2, c3 big.Int
+	c1.Sub(&n1, &n0)
+	c2.Sub(&n2, &n0)
+	c3.Sub(&n3, &n2).Sub(&c3, &n1).Add(&c3, &n0)
+
+	return cs.Add(n0, cs.Mul(b0, c1), cs.Mul(b1, c2), cs.Mul(b0b1, c3)) // no constraint is recorded
+}
+
+// LookupN returns values[i], where i is the integer whose bits (little endian) are b
+//
+// len(values) must be <= 2^len(b). If it is smaller, assertions ensure i < len(values).
+// The bits of b are asserted to be boolean.
+//
+// the selection costs len(values)-1 constraints at most; if the values are constants, the table is split
+// in groups of 4 entries selected by the 2 least significant bits (see Lookup2), and it costs about
+// len(values)/4 constraints
+func (cs *ConstraintSystem) LookupN(b []Variable, values ...interface{}) Variable {
+	if len(values) == 0 {
+		panic("LookupN: no values to select from")
+	}
+	if len(b) < bits.Len(uint(len(values)-1)) {
+		panic("LookupN: not enough bits to index values")
+	}
+
+	// the bits are asserted to be boolean once, the selections below don't repeat it
+	b = append([]Variable(nil), b...)
+	for i := 0; i < len(b); i++ {
+		cs.completeDanglingVariable(&b[i])
+		cs.AssertIsBoolean(b[i])
+		b[i].isBoolean = true
+	}
+
+	// ensures the index is < len(values)
+	if len(values) < 1<<len(b) {
+		var bound big.Int
+		bound.SetUint64(uint64(len(values) - 1))
+
+		var debugInfo logEntry
+		debugInfo.format = "index is out of range (> " + bound.String() + ")"
+		debugInfo.stack = getCallStack()
+
+		cs.mustBeLessOrEqCstBits(b, bound, debugInfo)
+	}
+
+	return cs.lookupN(b, values)
+}
+
+// lookupN returns values[i], where i is the integer whose bits (little endian) are b
+// (already asserted to be boolean, see LookupN)
+// indexes >= len(values) are never selected
+func (cs *ConstraintSystem) lookupN(b []Variable, values []interface{}) Variable {
+	if len(values) == 1 {
+		return cs.Constant(values[0])
+	}
+
+	constant := true
+	for i := 0; i < len(values) && constant; i++ {
+		_, isVar := values[i].(Variable)
+		constant = !isVar
+	}
+	if constant && len(values) > 2 {
+		// b0*b1 is shared by all the groups of 4 entries
+		return cs.lookupConstants(b, values, cs.Mul(b[0], b[1]))
+	}
+
+	// split the values on the most significant bit
+	msb := len(b) - 1
+	half := 1 << msb
+	if len(values) <= half {
+		// the most significant bit is 0
+		return cs.lookupN(b[:msb], values)
+	}
+	low := cs.lookupN(b[:msb], values[:half])
+	high := cs.lookupN(b[:msb], values[half:])
+
+	return cs.Select(b[msb], high, low)
+}
+
+// lookupConstants is like lookupN for a constant table, given b0b1 = b[0]*b[1]
+func (cs *ConstraintSystem) lookupConstants(b []Variable, values []interface{}, b0b1 Variable) Variable {
+	if len(values) == 1 {
+		return cs.Constant(values[0])
+	}
+	if len(b) == 2 {
+		// indexes >= len(values) are never selected, their entries are irrelevant
+		var table [4]interface{}
+		for i := 0; i < len(table); i++ {
+			table[i] = 0
+			if i < len(values) {
+				table[i] = values[i]
+			}
+		}
+		return cs.lookup2Constant(b[0], b[1], b0b1, table[0], table[1], table[2], table[3])
+	}
+
+	// split the values on the most significant bit
+	msb := len(b) - 1
+	half := 1 << msb
+	if len(values) <= half {
+		// the most significant bit is 0
+		return cs.lookupConstants(b[:msb], values, b0b1)
+	}
+	low := cs.lookupConstants(b[:msb], values[:half], b0b1)
+	high := cs.lookupConstants(b[:msb], values[half:], b0b1)
+
+	return cs.Select(b[msb], high, low)
+}
+
+// Mux returns values[selector]
+//
+// the selector is decomposed in binary (see ToBinary) and assertions ensure selector < len(values),
+// see LookupN for the constraint count of the selection itself
+func (cs *ConstraintSystem) Mux(selector Variable, values ...interface{}) Variable {
+	if len(values) == 0 {
+		panic("Mux: no values to select from")
+	}
+
+	cs.completeDanglingVariable(&selector)
+
+	if len(values) == 1 {
+		cs.AssertIsEqual(selector, 0)
+		return cs.Constant(values[0])
+	}
+
+	b := cs.ToBinary(selector, bits.Len(uint(len(values)-1)))
+	return cs.LookupN(b, values...)
+}
+
+// IsZero returns 1 if a is zero, 0 otherwise
+func (cs *ConstraintSystem) IsZero(a interface{}) Variable {
+
+	v, ok := a.(Variable)
+	if !ok {
+		// in this case, no constraint is recorded
+		n := backend.FromInterface(a)
+		if n.Sign() == 0 {
+			return cs.Constant(1)
+		}
+		return cs.Constant(0)
+	}
+	cs.completeDanglingVariable(&v)
+
+	// m = 1/a if a != 0, 0 otherwise
+	m := cs.NewHint(hint.InvZero, v)
+
+	// res = 1 - a*m
+	res := cs.newInternalVariable()
+	o := cs.Sub(1, res) // no constraint is recorded
+	constraint := r1c.R1C{L: v.getLinExpCopy(), R: m.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
+	cs.addConstraint(constraint)
+
+	// a * res = 0, which ensures res = 0 if a != 0 (and res = 1 otherwise, from the previous constraint)
+	zero := cs.Constant(0) // no constraint is recorded
+	assertion := r1c.R1C{L: v.getLinExpCopy(), R: res.getLinExpCopy(), O: zero.getLinExpCopy(), Solver: r1c.SingleOutput}
+
+	debugInfo := logEntry{
+		format:    "error IsZero",
+		toResolve: nil,
+	}
+	debugInfo.stack = getCallStack()
+	cs.addAssertion(assertion, debugInfo)
+
+	// res is boolean by construction
+	res.isBoolean = true
+
+	return res
+}
+
+// IsEqual returns 1 if i1 == i2, 0 otherwise
+func (cs *ConstraintSystem) IsEqual(i1, i2 interface{}) Variable {
+	return cs.IsZero(cs.Sub(i1, i2))
+}
+
+// Cmp compares i1 and i2, seen as integers of nbBits bits
+//
+// it returns 1 if i1 > i2, 0 if i1 == i2 and -1 if i1 < i2. The solver fails if i1 or i2 doesn't fit in nbBits bits.
+// nbBits must be below the bit length of the scalar field, so that the binary decompositions of i1 and i2 are
+// unique: otherwise, a small value x could also be decomposed as x + modulus, flipping the result
+func (cs *ConstraintSystem) Cmp(i1, i2 interface{}, nbBits int) Variable {
+	if max := cs.maxNbBits(); nbBits <= 0 || nbBits > max {
+		panic("Cmp: nbBits must be in [1, " + fmt.Sprint(max) + "]")
+	}
+
+	decompose := func(i interface{}) []Variable {
+		v := cs.Constant(i) // no constraint is recorded
+		var debugInfo logEntry
+		cs.appendToLogEntry(&debugInfo, v)
+		debugInfo.format += " doesn't fit in " + fmt.Sprint(nbBits) + " bits"
+		appendCallStack(&debugInfo)
+		return cs.toBinaryHint(v, nbBits, debugInfo)
+	}
+	bi1 := decompose(i1)
+	bi2 := decompose(i2)
+
+	// from the least significant bit to the most significant one:
+	// res = d if the i-th bits differ (d = bi1[i] - bi2[i] = 1 or -1), res is left unchanged otherwise
+	res := cs.Sub(bi1[0], bi2[0]) // no constraint is recorded
+	for i := 1; i < nbBits; i++ {
+		d := cs.Sub(bi1[i], bi2[i]) // no constraint is recorded
+
+		// e = 1 - d*d = 1 - bi1[i] - bi2[i] + 2*bi1[i]*bi2[i] is 1 if the bits are equal, 0 otherwise
+		e := cs.Mul(bi1[i], bi2[i])
+		e = cs.Mul(e, 2)                                 // no constraint is recorded
+		e = cs.Sub(cs.Add(e, 1), cs.Add(bi1[i], bi2[i])) // no constraint is recorded
+
+		res = cs.Add(d, cs.Mul(e, res))
+	}
+
+	return res
+}
+
+// IsLess returns 1 if i1 < i2, 0 otherwise
+//
+// i1 and i2 are seen as integers of the largest number of bits Cmp supports on the curve
+// (the bit length of the scalar field, minus 1)
+func (cs *ConstraintSystem) IsLess(i1, i2 interface{}) Variable {
+	return cs.IsEqual(cs.Cmp(i1, i2, cs.maxNbBits()), -1)
+}
+
+// DivMod returns the quotient q and the remainder r of the euclidean division of a by b,
+// seen as integers of nbBits bits: a = q*b + r and 0 <= r < b
+//
+// unlike Div, which multiplies by an inverse in the scalar field, DivMod follows the semantics
+// of big.Int. q and r are computed by the solver (see NewHint), then a, b, q and r are range checked
+// to nbBits bits, which costs about 5*nbBits constraints. nbBits must be at most 126 so that
+// q*b + r doesn't overflow the scalar field (the smallest supported one has 253 bits).
+func (cs *ConstraintSystem) DivMod(a, b interface{}, nbBits int) (q, r Variable) {
+	const maxNbBits = 126
+	if nbBits <= 0 || nbBits > maxNbBits {
+		panic("DivMod: nbBits must be in [1, 126]")
+	}
+
+	// checks that the constant inputs fit in nbBits bits, and decomposes the others
+	rangeCheck := func(i interface{}) {
+		if v, ok := i.(Variable); ok {
+			cs.ToBinary(v, nbBits)
+			return
+		}
+		n := backend.FromInterface(i)
+		if n.Sign() < 0 || n.BitLen() > nbBits {
+			panic("DivMod: " + n.String() + " doesn't fit in " + fmt.Sprint(nbBits) + " bits")
+		}
+	}
+
+	_, va := a.(Variable)
+	_, vb := b.(Variable)
+	if !va && !vb {
+		// in this case, no constraint is recorded
+		rangeCheck(a)
+		rangeCheck(b)
+		na := backend.FromInterface(a)
+		nb := backend.FromInterface(b)
+		if nb.Sign() == 0 {
+			panic("DivMod: division by zero")
+		}
+		var nq, nr big.Int
+		nq.DivMod(&na, &nb, &nr)
+		return cs.Constant(nq), cs.Constant(nr)
+	}
+
+	q = cs.NewHint(hint.IntDiv, a, b)
+	r = cs.NewHint(hint.IntMod, a, b)
+
+	rangeCheck(a)
+	rangeCheck(b)
+	rangeCheck(q)
+	rangeCheck(r)
+
+	// a, b, q and r are < 2^nbBits, so q*b + r doesn't wrap around the modulus
+	cs.AssertIsEqual(cs.Add(cs.Mul(q, b), r), a)
+
+	// r < b <=> b - r - 1 fits in nbBits bits (b - r - 1 is in ]-2^nbBits-1, 2^nbBits[)
+	cs.ToBinary(cs.Sub(cs.Sub(b, r), 1), nbBits)
+
+	return q, r
+}
+
+// NewHint returns a new internal variable whose value is computed by f when the R1CS is solved
+//
+// inputs can be Variables or must be convertible to big.Int (see backend.FromInterface)
+//
+// the returned variable is NOT constrained by the hint: it is the caller responsability to
+// add the constraints ensuring the result is correct. f is registered in the hint registry
+// (see package backend/hint); it must be registered again in a process solving a deserialized R1CS
+func (cs *ConstraintSystem) NewHint(f hint.Function, inputs ...interface{}) Variable {
+
+	h := r1c.Hint{
+		ID:     hint.Register(f),
+		Inputs: make([]r1c.LinearExpression, len(inputs)),
+	}
+
+	for i := 0; i < len(inputs); i++ {
+		v := cs.Constant(inputs[i]) // no constraint is recorded
+		h.Inputs[i] = v.getLinExpCopy()
+	}
+
+	res := cs.newInternalVariable()
+	h.WireID = res.id
+	cs.hints = append(cs.hints, h)
+	if cs.engine != nil {
+		cs.executeHint(h)
+	}
+
+	return res
+}
+
+// Define adds the constraints of a sub-circuit to the constraint system, and records its boundaries
+// in the R1CS (see r1c.Component) under name. The component boundaries give per-component statistics
+// (see r1cs.GetComponentStats) and the path of the component in solver errors.
+//
+// As for a Circuit, the inputs and outputs of the component are its Variables: the inputs must be
+// set before calling Define, and component.Define must set the outputs. Define returns an error
+// if a Variable of the component is still unset afterwards.
+func (cs *ConstraintSystem) Define(name string, component Circuit) error {
+
+	id := len(cs.components)
+	parent := -1
+	if len(cs.componentStack) > 0 {
+		parent = cs.componentStack[len(cs.componentStack)-1]
+	}
+	cs.components = append(cs.components, r1c.Component{
+		Name:        name,
+		Parent:      parent,
+		Constraints: [2]int{len(cs.constraints), len(cs.constraints)},
+		Assertions:  [2]int{len(cs.assertions), len(cs.assertions)},
+	})
+
+	// the range checks requested by the component are added in its boundaries
+	rangeChecks := cs.rangeChecks
+	cs.rangeChecks = nil
+
+	cs.componentStack = append(cs.componentStack, id)
+	err := component.Define(cs.curveID, cs)
+	if err == nil {
+		cs.flushRangeChecks()
+	}
+	cs.componentStack = cs.componentStack[:len(cs.componentStack)-1]
+	cs.rangeChecks = rangeChecks
+
+	cs.components[id].Constraints[1] = len(cs.constraints)
+	cs.components[id].Assertions[1] = len(cs.assertions)
+
+	if err != nil {
+		return fmt.Errorf("component %s: %w", r1c.ComponentPath(cs.components, id), err)
+	}
+
+	// all the inputs and outputs must be set
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		v := tInput.Interface().(Variable)
+		if len(v.linExp) == 0 {
+			return fmt.Errorf("component %s: %w: %s", r1c.ComponentPath(cs.components, id), backend.ErrInputNotSet, name)
+		}
+		return nil
+	}
+	if cs.strictParsing {
+		return parseTypeStrict(component, "", backend.Unset, handler)
+	}
+	return parseType(component, "", backend.Unset, handler)
+}
+
 // Constant will return (and allocate if neccesary) a constant Variable
 //
 // input can be a Variable or must be convertible to big.Int (see backend.FromInterface)
@@ -366,6 +777,24 @@ func (cs *ConstraintSystem) buildLogEntryFromVariable(v Variable) logEntry {
 	return res
 }
 
+// appendToLogEntry appends i (a Variable or a constant) to the log entry
+// if i is a Variable, it must not be dangling
+func (cs *ConstraintSystem) appendToLogEntry(entry *logEntry, i interface{}) {
+	if v, ok := i.(Variable); ok {
+		e := cs.buildLogEntryFromVariable(v)
+		entry.format += e.format
+		entry.toResolve = append(entry.toResolve, e.toResolve...)
+		return
+	}
+	n := backend.FromInterface(i)
+	entry.format += n.String()
+}
+
+// appendCallStack records the call stack in the log entry
+func appendCallStack(entry *logEntry) {
+	entry.stack = getCallStack()
+}
+
 // AssertIsEqual adds an assertion in the constraint system (i1 == i2)
 func (cs *ConstraintSystem) AssertIsEqual(i1, i2 interface{}) {
 
@@ -395,7 +824,37 @@ func (cs *ConstraintSystem) AssertIsEqual(i1, i2 interface{}) {
 	cs.addAssertion(constraint, debugInfo)
 }
 
-// AssertIsBoolean adds an assertion in the constraint system (v == 0 || v == 1)
+// AssertIsInSet adds an assertion in the constraint system (v == values[0] || v == values[1] || ...)
+//
+// values can be Variables or constants, it costs len(values)-2 constraints and one assertion
+func (cs *ConstraintSystem) AssertIsInSet(v Variable, values ...interface{}) {
+	if len(values) == 0 {
+		panic("AssertIsInSet: empty set")
+	}
+
+	cs.completeDanglingVariable(&v)
+
+	// prepare debug info to be displayed in case the constraint is not solved
+	debugInfo := cs.buildLogEntryFromVariable(v)
+	debugInfo.format += " is not in the set"
+	debugInfo.stack = getCallStack()
+
+	// (v - values[0]) * (v - values[1]) * ... == 0
+	l := cs.Sub(v, values[0]) // no constraint is recorded
+	for i := 1; i < len(values)-1; i++ {
+		l = cs.Mul(l, cs.Sub(v, values[i]))
+	}
+	r := cs.Constant(1) // no constraint is recorded
+	if len(values) > 1 {
+		r = cs.Sub(v, values[len(values)-1]) // no constraint is recorded
+	}
+	o := cs.Constant(0) // no constraint is recorded
+
+	constraint := r1c.R1C{L: l.getLinExpCopy(), R: r.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
+	cs.addAssertion(constraint, debugInfo)
+}
+
+// AssertIsBoolean adds an assertion in the constraint system (v == 0 || v == 1)
 func (cs *ConstraintSystem) AssertIsBoolean(v Variable) {
 
 	cs.completeDanglingVariable(&v)
@@ -404,12 +863,6 @@ func (cs *ConstraintSystem) AssertIsBoolean(v Variable) {
 		return
 	}
 
-	_v := cs.Sub(1, v)  // no variable is recorded in the cs
-	o := cs.Constant(0) // no variable is recorded in the cs
-	v.isBoolean = true
-
-	constraint := r1c.R1C{L: v.getLinExpCopy(), R: _v.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
-
 	// prepare debug info to be displayed in case the constraint is not solved
 	// debugInfo := logEntry{
 	// 	format:    fmt.Sprintf("%%s == (0 or 1)"),
@@ -420,54 +873,179 @@ func (cs *ConstraintSystem) AssertIsBoolean(v Variable) {
 		format:    fmt.Sprintf("error AssertIsBoolean"),
 		toResolve: nil,
 	}
-	stack := getCallStack()
-	for i := 0; i < len(stack); i++ {
-		debugInfo.format += "\n" + stack[i]
-	}
+	debugInfo.stack = getCallStack()
+
+	cs.assertIsBoolean(v, debugInfo)
+}
+
+// assertIsBoolean is like AssertIsBoolean, but the assertion fails with debugInfo
+// (for the bits of a decomposition done on behalf of another assertion)
+func (cs *ConstraintSystem) assertIsBoolean(v Variable, debugInfo logEntry) {
+
+	_v := cs.Sub(1, v)  // no variable is recorded in the cs
+	o := cs.Constant(0) // no variable is recorded in the cs
 
+	constraint := r1c.R1C{L: v.getLinExpCopy(), R: _v.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
 	cs.addAssertion(constraint, debugInfo)
 }
 
 // AssertIsLessOrEqual adds assertion in constraint system  (v <= bound)
 //
-// bound can be a constant or a Variable
+// bound can be a constant or a Variable. v (and bound, if it is a Variable) must fit in one bit less
+// than the modulus of the curve, so that its binary decomposition is unique
 //
 // derived from:
 // https://github.com/zcash/zips/blOoutputb/master/protocol/protocol.pdf
 func (cs *ConstraintSystem) AssertIsLessOrEqual(v Variable, bound interface{}) {
 
 	cs.completeDanglingVariable(&v)
+	if b, ok := bound.(Variable); ok {
+		cs.completeDanglingVariable(&b)
+		bound = b
+	}
+
+	// prepare debug info to be displayed in case the constraint is not solved
+	var debugInfo logEntry
+	cs.appendToLogEntry(&debugInfo, v)
+	debugInfo.format += " <= "
+	cs.appendToLogEntry(&debugInfo, bound)
+	appendCallStack(&debugInfo)
 
 	switch b := bound.(type) {
 	case Variable:
-		cs.completeDanglingVariable(&b)
-		cs.mustBeLessOrEqVar(v, b)
+		cs.mustBeLessOrEqVar(v, b, debugInfo)
 	default:
-		cs.mustBeLessOrEqCst(v, backend.FromInterface(b))
+		cs.mustBeLessOrEqCst(v, backend.FromInterface(b), debugInfo)
+	}
+
+}
+
+// AssertIsLess adds assertion in constraint system (v < bound)
+//
+// bound can be a constant or a Variable, see AssertIsLessOrEqual.
+// If bound is a Variable, it costs one more assertion than AssertIsLessOrEqual (v != bound)
+func (cs *ConstraintSystem) AssertIsLess(v Variable, bound interface{}) {
+
+	cs.completeDanglingVariable(&v)
+	if b, ok := bound.(Variable); ok {
+		cs.completeDanglingVariable(&b)
+		bound = b
 	}
 
+	// prepare debug info to be displayed in case the constraint is not solved
+	var debugInfo logEntry
+	cs.appendToLogEntry(&debugInfo, v)
+	debugInfo.format += " < "
+	cs.appendToLogEntry(&debugInfo, bound)
+	appendCallStack(&debugInfo)
+
+	switch b := bound.(type) {
+	case Variable:
+		cs.mustBeLessOrEqVar(v, b, debugInfo)
+
+		// v != bound <=> v - bound is invertible
+		d := cs.Sub(v, b)                // no constraint is recorded
+		m := cs.NewHint(hint.InvZero, d) // no constraint is recorded
+		o := cs.getOneVariable()         // no constraint is recorded
+		constraint := r1c.R1C{L: d.getLinExpCopy(), R: m.getLinExpCopy(), O: o.getLinExpCopy(), Solver: r1c.SingleOutput}
+		cs.addAssertion(constraint, debugInfo)
+	default:
+		n := backend.FromInterface(b)
+		if n.Sign() <= 0 {
+			panic("AssertIsLess: no value is < " + n.String())
+		}
+		n.Sub(&n, bOne)
+		cs.mustBeLessOrEqCst(v, n, debugInfo)
+	}
 }
 
-func (cs *ConstraintSystem) mustBeLessOrEqVar(w, bound Variable) {
+// AssertIsInRange adds assertions in constraint system (lo <= v <= hi)
+//
+// lo and hi can be constants or Variables, see AssertIsLessOrEqual.
+// If both are constants, only v - lo is decomposed in binary
+func (cs *ConstraintSystem) AssertIsInRange(v Variable, lo, hi interface{}) {
+
+	cs.completeDanglingVariable(&v)
+	if b, ok := lo.(Variable); ok {
+		cs.completeDanglingVariable(&b)
+		lo = b
+	}
+	if b, ok := hi.(Variable); ok {
+		cs.completeDanglingVariable(&b)
+		hi = b
+	}
 
 	// prepare debug info to be displayed in case the constraint is not solved
-	dbgInfoW := cs.buildLogEntryFromVariable(w)
-	dbgInfoBound := cs.buildLogEntryFromVariable(bound)
 	var debugInfo logEntry
-	debugInfo.format = dbgInfoW.format + " <= " + dbgInfoBound.format
-	debugInfo.toResolve = make([]r1c.Term, len(dbgInfoW.toResolve)+len(dbgInfoBound.toResolve))
-	copy(debugInfo.toResolve[:], dbgInfoW.toResolve)
-	copy(debugInfo.toResolve[len(dbgInfoW.toResolve):], dbgInfoBound.toResolve)
+	cs.appendToLogEntry(&debugInfo, lo)
+	debugInfo.format += " <= "
+	cs.appendToLogEntry(&debugInfo, v)
+	debugInfo.format += " <= "
+	cs.appendToLogEntry(&debugInfo, hi)
+	appendCallStack(&debugInfo)
+
+	vLo, loIsVar := lo.(Variable)
+	vHi, hiIsVar := hi.(Variable)
+
+	if !loIsVar && !hiIsVar {
+		nLo := backend.FromInterface(lo)
+		nHi := backend.FromInterface(hi)
+		if nHi.Cmp(&nLo) < 0 {
+			panic("AssertIsInRange: the range [" + nLo.String() + ", " + nHi.String() + "] is empty")
+		}
+		// lo <= v <= hi <=> v - lo <= hi - lo
+		var width big.Int
+		width.Sub(&nHi, &nLo)
+		cs.mustBeLessOrEqCst(cs.Sub(v, nLo), width, debugInfo)
+		return
+	}
+
+	// lo <= v
+	if !loIsVar {
+		vLo = cs.Constant(lo) // no constraint is recorded
+	}
+	cs.mustBeLessOrEqVar(vLo, v, debugInfo)
 
-	stack := getCallStack()
-	for i := 0; i < len(stack); i++ {
-		debugInfo.format += "\n" + stack[i]
+	// v <= hi
+	if hiIsVar {
+		cs.mustBeLessOrEqVar(v, vHi, debugInfo)
+	} else {
+		cs.mustBeLessOrEqCst(v, backend.FromInterface(hi), debugInfo)
+	}
+}
+
+// RangeCheck adds assertions in constraint system (0 <= v < 2^nbBits)
+//
+// the check is deferred until the end of the current component (see Define), or until the circuit
+// is compiled, and batched with the other range checks of the component: a variable checked several
+// times is decomposed in binary once, for the smallest nbBits, and a previous decomposition (from ToBinary
+// or another range check) is reused when it costs less than a new one.
+// A new decomposition costs nbBits assertions and no computational constraint, the least a R1CS
+// can do per variable: the range checks of distinct variables can't share their bits soundly.
+func (cs *ConstraintSystem) RangeCheck(v Variable, nbBits int) {
+	if nbBits <= 0 {
+		panic("RangeCheck: nbBits must be positive")
 	}
 
-	const nbBits = 256
+	cs.completeDanglingVariable(&v)
+
+	// prepare debug info to be displayed in case the constraint is not solved
+	var debugInfo logEntry
+	cs.appendToLogEntry(&debugInfo, v)
+	debugInfo.format += " < 2^" + fmt.Sprint(nbBits)
+	appendCallStack(&debugInfo)
+
+	cs.rangeChecks = append(cs.rangeChecks, rangeCheck{v: v, nbBits: nbBits, debugInfo: debugInfo})
+}
+
+// mustBeLessOrEqVar ensures w <= bound, both decomposed in cs.maxNbBits() bits so that their decompositions
+// are unique: it fails with debugInfo if w or bound doesn't fit
+func (cs *ConstraintSystem) mustBeLessOrEqVar(w, bound Variable, debugInfo logEntry) {
 
-	binw := cs.ToBinary(w, nbBits)
-	binbound := cs.ToBinary(bound, nbBits)
+	nbBits := cs.maxNbBits()
+
+	binw := cs.toBinaryHint(w, nbBits, debugInfo)
+	binbound := cs.toBinaryHint(bound, nbBits, debugInfo)
 
 	p := make([]Variable, nbBits+1)
 	p[nbBits] = cs.Constant(1)
@@ -494,54 +1072,45 @@ func (cs *ConstraintSystem) mustBeLessOrEqVar(w, bound Variable) {
 
 }
 
-func (cs *ConstraintSystem) mustBeLessOrEqCst(v Variable, bound big.Int) {
-
-	// prepare debug info to be displayed in case the constraint is not solved
-	dbgInfoW := cs.buildLogEntryFromVariable(v)
-	var debugInfo logEntry
-	debugInfo.format = dbgInfoW.format + " <= " + bound.String()
-	debugInfo.toResolve = dbgInfoW.toResolve
+// mustBeLessOrEqCst ensures v <= bound: it fails with debugInfo if v doesn't fit in cs.maxNbBits() bits
+// (or in the bits of bound, if there are more)
+func (cs *ConstraintSystem) mustBeLessOrEqCst(v Variable, bound big.Int, debugInfo logEntry) {
 
-	stack := getCallStack()
-	for i := 0; i < len(stack); i++ {
-		debugInfo.format += "\n" + stack[i]
+	// a decomposition in more bits isn't unique, but only its smallest value can be <= bound, as bound
+	// is smaller than the modulus
+	nbBits := cs.maxNbBits()
+	if bound.BitLen() > nbBits {
+		nbBits = bound.BitLen()
 	}
 
-	// TODO store those constant elsewhere (for the moment they don't depend on the base curve, but that might change)
-	const nbBits = 256
-	const nbWords = 4
-	const wordSize = 64
+	vBits := cs.toBinaryHint(v, nbBits, debugInfo)
+	cs.mustBeLessOrEqCstBits(vBits, bound, debugInfo)
+}
 
-	vBits := cs.ToBinary(v, nbBits)
-	boundBits := bound.Bits()
-	l := len(boundBits)
-	if len(boundBits) < nbWords {
-		for i := 0; i < nbWords-l; i++ {
-			boundBits = append(boundBits, big.Word(0))
-		}
-	}
+// mustBeLessOrEqCstBits ensures the integer whose bits (little endian) are vBits is <= bound
+// bound must fit in len(vBits) bits
+func (cs *ConstraintSystem) mustBeLessOrEqCstBits(vBits []Variable, bound big.Int, debugInfo logEntry) {
+
+	nbBits := len(vBits)
 
 	p := make([]Variable, nbBits+1)
 
 	p[nbBits] = cs.Constant(1)
-	for i := nbWords - 1; i >= 0; i-- {
-		for j := 0; j < wordSize; j++ {
-			b := (boundBits[i] >> (wordSize - 1 - j)) & 1
-			if b == 0 {
-				p[(i+1)*wordSize-1-j] = p[(i+1)*wordSize-j]
-
-				l := cs.getOneVariable()
-				l = cs.Sub(l, p[(i+1)*wordSize-j])       // no constraint is recorded
-				l = cs.Sub(l, vBits[(i+1)*wordSize-1-j]) // no constraint is recorded
-
-				r := vBits[(i+1)*wordSize-1-j]
-				o := cs.Constant(0)
-				constraint := r1c.R1C{L: l.linExp, R: r.linExp, O: o.linExp, Solver: r1c.SingleOutput}
-				cs.addAssertion(constraint, debugInfo)
-
-			} else {
-				p[(i+1)*wordSize-1-j] = cs.Mul(p[(i+1)*wordSize-j], vBits[(i+1)*wordSize-1-j])
-			}
+	for i := nbBits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 0 {
+			p[i] = p[i+1]
+
+			l := cs.getOneVariable()
+			l = cs.Sub(l, p[i+1])   // no constraint is recorded
+			l = cs.Sub(l, vBits[i]) // no constraint is recorded
+
+			r := vBits[i]
+			o := cs.Constant(0)
+			constraint := r1c.R1C{L: l.linExp, R: r.linExp, O: o.linExp, Solver: r1c.SingleOutput}
+			cs.addAssertion(constraint, debugInfo)
+
+		} else {
+			p[i] = cs.Mul(p[i+1], vBits[i])
 		}
 	}
 }
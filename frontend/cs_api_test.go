@@ -2,10 +2,16 @@ package frontend
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	backend_r1cs "github.com/consensys/gnark/backend/r1cs"
 	"github.com/consensys/gnark/backend/r1cs/r1c"
 	"github.com/consensys/gurvy"
 	"github.com/leanovate/gopter"
@@ -443,6 +449,66 @@ func rfIsEqual() runfunc {
 
 var nsIsEqual = deltaState{1, 1, 0, 0, 2}
 
+// zero test of a variable
+func rfIsZero() runfunc {
+	res := func(systemUnderTest commands.SystemUnderTest) commands.Result {
+
+		pVariablesCreated := make([]Variable, 0)
+		sVariablesCreated := make([]Variable, 0)
+		iVariablesCreated := make([]Variable, 0)
+
+		a := systemUnderTest.(*ConstraintSystem).newPublicVariable(variableName.String())
+		incVariableName()
+		pVariablesCreated = append(pVariablesCreated, a)
+
+		systemUnderTest.(*ConstraintSystem).IsZero(a)
+
+		csRes := csResult{
+			systemUnderTest.(*ConstraintSystem),
+			pVariablesCreated,
+			sVariablesCreated,
+			iVariablesCreated,
+			r1c.SingleOutput}
+
+		return csRes
+	}
+	return res
+}
+
+var nsIsZero = deltaState{1, 0, 2, 1, 1}
+
+// 2-bit lookup in a constant table
+func rfLookup2() runfunc {
+	res := func(systemUnderTest commands.SystemUnderTest) commands.Result {
+
+		pVariablesCreated := make([]Variable, 0)
+		sVariablesCreated := make([]Variable, 0)
+		iVariablesCreated := make([]Variable, 0)
+
+		b0 := systemUnderTest.(*ConstraintSystem).newPublicVariable(variableName.String())
+		incVariableName()
+		pVariablesCreated = append(pVariablesCreated, b0)
+
+		b1 := systemUnderTest.(*ConstraintSystem).newPublicVariable(variableName.String())
+		incVariableName()
+		pVariablesCreated = append(pVariablesCreated, b1)
+
+		systemUnderTest.(*ConstraintSystem).Lookup2(b0, b1, 12, 3, 42, 7)
+
+		csRes := csResult{
+			systemUnderTest.(*ConstraintSystem),
+			pVariablesCreated,
+			sVariablesCreated,
+			iVariablesCreated,
+			r1c.SingleOutput}
+
+		return csRes
+	}
+	return res
+}
+
+var nsLookup2 = deltaState{2, 0, 1, 1, 2}
+
 // packing from binary variables
 func rfFromBinary() runfunc {
 	res := func(systemUnderTest commands.SystemUnderTest) commands.Result {
@@ -600,6 +666,8 @@ func TestAPI(t *testing.T) {
 		buildProtoCommands("Select 2 variables", rfSelect(), nextStateFunc(nsSelect)),
 		buildProtoCommands("Constant", rfConstant(), nextStateFunc(nsConstant)),
 		buildProtoCommands("IsEqual", rfIsEqual(), nextStateFunc(nsIsEqual)),
+		buildProtoCommands("IsZero", rfIsZero(), nextStateFunc(nsIsZero)),
+		buildProtoCommands("Lookup2", rfLookup2(), nextStateFunc(nsLookup2)),
 		buildProtoCommands("FromBinary", rfFromBinary(), nextStateFunc(nsFromBinary)),
 		buildProtoCommands("IsBoolean", rfIsBoolean(), nextStateFunc(nsIsBoolean)), // TODO fix isBoolean to record if it was already boolean constrained
 		// buildProtoCommands("Must be less or eq var", rfMustBeLessOrEqVar(), nextStateFunc(nsMustBeLessOrEqVar)), // TODO restore once isBoolean is fixed
@@ -609,7 +677,7 @@ func TestAPI(t *testing.T) {
 	// generate randomly a sequence of commands
 	var apiCommands = &commands.ProtoCommands{
 		NewSystemUnderTestFunc: func(initialState commands.State) commands.SystemUnderTest {
-			nc := newConstraintSystem()
+			nc := newConstraintSystem(0)
 			return &nc
 		},
 		InitialStateGen: gen.Const(1).Map(func(npv int) *csState {
@@ -761,6 +829,67 @@ func (c *isLessOrEq) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
 	return nil
 }
 
+type isZeroCircuit struct {
+	A Variable
+}
+
+func (c *isZeroCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.IsZero(unsetVar)
+	return nil
+}
+
+type muxCircuit struct {
+	A Variable
+}
+
+func (c *muxCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.Mux(unsetVar, c.A, 1, 2)
+	return nil
+}
+
+type isInSetCircuit struct {
+	A Variable
+}
+
+func (c *isInSetCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.AssertIsInSet(unsetVar, c.A, 1, 2)
+	return nil
+}
+
+type isLessCircuit struct {
+	A Variable
+}
+
+func (c *isLessCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.AssertIsLess(c.A, unsetVar)
+	return nil
+}
+
+type isInRangeCircuit struct {
+	A Variable
+}
+
+func (c *isInRangeCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.AssertIsInRange(c.A, unsetVar, 10)
+	return nil
+}
+
+type rangeCheckCircuit struct {
+	A Variable
+}
+
+func (c *rangeCheckCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	var unsetVar Variable
+	cs.RangeCheck(c.A, 8)
+	cs.RangeCheck(unsetVar, 8)
+	return nil
+}
+
 func TestUnsetVariables(t *testing.T) {
 
 	mapFuncs := map[string]Circuit{
@@ -776,6 +905,12 @@ func TestUnsetVariables(t *testing.T) {
 		"isEqual":      &isEqualCircuit{},
 		"isBoolean":    &isBooleanCircuit{},
 		"isLessOrEq":   &isLessOrEq{},
+		"isZero":       &isZeroCircuit{},
+		"mux":          &muxCircuit{},
+		"isInSet":      &isInSetCircuit{},
+		"isLess":       &isLessCircuit{},
+		"isInRange":    &isInRangeCircuit{},
+		"rangeCheck":   &rangeCheckCircuit{},
 	}
 
 	for name, arg := range mapFuncs {
@@ -792,3 +927,785 @@ func TestUnsetVariables(t *testing.T) {
 	}
 
 }
+
+// ------------------------------------------------------------------------------
+// Test the boolean-returning comparisons by solving the constraint system on all curves
+
+type comparisonCircuit struct {
+	A, B                      Variable
+	IsZero, IsEqual, IsLess   Variable `gnark:",public"`
+	Cmp                       Variable `gnark:",public"`
+	IsZeroCst, IsLessCst, Sel Variable `gnark:",public"`
+}
+
+func (c *comparisonCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(cs.IsZero(c.A), c.IsZero)
+	cs.AssertIsEqual(cs.IsEqual(c.A, c.B), c.IsEqual)
+	cs.AssertIsEqual(cs.IsLess(c.A, c.B), c.IsLess)
+	cs.AssertIsEqual(cs.Cmp(c.A, c.B, 8), c.Cmp)
+	cs.AssertIsEqual(cs.IsZero(cs.Sub(c.A, 3)), c.IsZeroCst)
+	cs.AssertIsEqual(cs.IsLess(c.A, 200), c.IsLessCst)
+	cs.AssertIsEqual(cs.Select(cs.IsLess(c.A, c.B), c.A, c.B), c.Sel)
+	return nil
+}
+
+func TestComparisons(t *testing.T) {
+
+	// a, b, isZero, isEqual, isLess, cmp, isZeroCst (a == 3), isLessCst (a < 200), sel (min(a,b))
+	testData := [][9]int{
+		{0, 0, 1, 1, 0, 0, 0, 1, 0},
+		{0, 5, 1, 0, 1, -1, 0, 1, 0},
+		{3, 3, 0, 1, 0, 0, 1, 1, 3},
+		{7, 3, 0, 0, 0, 1, 0, 1, 3},
+		{200, 201, 0, 0, 1, -1, 0, 0, 200},
+		{255, 128, 0, 0, 0, 1, 0, 0, 128},
+	}
+
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	for _, curveID := range curves {
+		var circuit comparisonCircuit
+		r1cs, err := Compile(curveID, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, d := range testData {
+			witness := map[string]interface{}{
+				"A":         d[0],
+				"B":         d[1],
+				"IsZero":    d[2],
+				"IsEqual":   d[3],
+				"IsLess":    d[4],
+				"Cmp":       d[5],
+				"IsZeroCst": d[6],
+				"IsLessCst": d[7],
+				"Sel":       d[8],
+			}
+			if err := r1cs.IsSolved(witness); err != nil {
+				t.Fatal(curveID.String(), d, err)
+			}
+
+			// flipping any expected output must make the solver fail
+			for _, name := range []string{"IsZero", "IsEqual", "IsLess", "IsZeroCst", "IsLessCst"} {
+				witness[name] = 1 - witness[name].(int)
+				if err := r1cs.IsSolved(witness); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+					t.Fatal(curveID.String(), d, name, "expected unsatisfied constraint error")
+				}
+				witness[name] = 1 - witness[name].(int)
+			}
+			witness["Cmp"] = d[5] + 1
+			if err := r1cs.IsSolved(witness); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+				t.Fatal(curveID.String(), d, "Cmp", "expected unsatisfied constraint error")
+			}
+		}
+	}
+}
+
+type isLessDecompositionCircuit struct {
+	A, B   Variable
+	IsLess Variable `gnark:",public"`
+}
+
+func (c *isLessDecompositionCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(cs.IsLess(c.A, c.B), c.IsLess)
+	return nil
+}
+
+// ithBitShifted is a dishonest hint decomposing 1 as 1 + modulus, which is equal to 1 in the field
+func ithBitShifted(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if inputs[0].Cmp(big.NewInt(1)) != 0 {
+		return hint.IthBit(modulus, inputs, result)
+	}
+	var shifted big.Int
+	shifted.Add(inputs[0], modulus)
+	return hint.IthBit(modulus, []*big.Int{&shifted, inputs[1]}, result)
+}
+
+func TestIsLessDishonestDecomposition(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	for _, curveID := range curves {
+		var circuit isLessDecompositionCircuit
+		res, err := Compile(curveID, &circuit, WithUntypedR1CS())
+		if err != nil {
+			t.Fatal(err)
+		}
+		untyped := res.(*backend_r1cs.UntypedR1CS)
+
+		// 1 < 2, but 1 + modulus > 2: the decompositions must be too small for 1 + modulus
+		dishonest := *untyped
+		dishonest.Hints = make([]r1c.Hint, len(untyped.Hints))
+		for i, h := range untyped.Hints {
+			if h.ID == hint.UUID(hint.IthBit) {
+				h.ID = hint.Register(ithBitShifted)
+			}
+			dishonest.Hints[i] = h
+		}
+
+		if err := untyped.ToR1CS(curveID).IsSolved(map[string]interface{}{"A": 1, "B": 2, "IsLess": 1}); err != nil {
+			t.Fatal(curveID.String(), err)
+		}
+		r1cs := dishonest.ToR1CS(curveID)
+		for _, isLess := range []int{0, 1} {
+			if err := r1cs.IsSolved(map[string]interface{}{"A": 1, "B": 2, "IsLess": isLess}); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+				t.Fatal(curveID.String(), isLess, "expected unsatisfied constraint error, got", err)
+			}
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Test the lookup gadgets by solving the constraint system on all curves
+
+type lookupCircuit struct {
+	B0, B1, Sel, X, Y       Variable
+	Lookup2Cst, Lookup2Var  Variable `gnark:",public"`
+	MuxCst, MuxVar, MuxPow2 Variable `gnark:",public"`
+}
+
+func (c *lookupCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(cs.Lookup2(c.B0, c.B1, 10, 11, 12, 13), c.Lookup2Cst)
+	cs.AssertIsEqual(cs.Lookup2(c.B0, c.B1, c.X, 11, c.Y, 13), c.Lookup2Var)
+	cs.AssertIsEqual(cs.Mux(c.Sel, 100, 101, 102, 103, 104), c.MuxCst)
+	cs.AssertIsEqual(cs.Mux(c.Sel, c.X, 101, c.Y, cs.Add(c.X, c.Y), 104), c.MuxVar)
+	cs.AssertIsEqual(cs.Mux(c.Sel, 0, 1, 2, 3, 4, 5, 6, 7), c.MuxPow2)
+	cs.AssertIsInSet(c.Sel, 0, 1, 2, cs.Sub(c.X, 39), 4)
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	const x, y = 42, 1000
+	muxVar := []int{x, 101, y, x + y, 104}
+
+	for _, curveID := range curves {
+		var circuit lookupCircuit
+		r1cs, err := Compile(curveID, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		witness := func(b0, b1, sel int) map[string]interface{} {
+			return map[string]interface{}{
+				"B0":         b0,
+				"B1":         b1,
+				"Sel":        sel,
+				"X":          x,
+				"Y":          y,
+				"Lookup2Cst": 10 + b0 + 2*b1,
+				"Lookup2Var": []int{x, 11, y, 13}[b0+2*b1],
+				"MuxCst":     100 + sel,
+				"MuxVar":     muxVar[sel%len(muxVar)],
+				"MuxPow2":    sel,
+			}
+		}
+
+		for b := 0; b < 4; b++ {
+			for sel := 0; sel < 5; sel++ {
+				if err := r1cs.IsSolved(witness(b&1, b>>1, sel)); err != nil {
+					t.Fatal(curveID.String(), b, sel, err)
+				}
+			}
+		}
+
+		// wrong lookup result
+		w := witness(1, 0, 2)
+		w["Lookup2Var"] = y
+		if err := r1cs.IsSolved(w); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+			t.Fatal(curveID.String(), "expected unsatisfied constraint error")
+		}
+
+		// selector out of range (and not in the set)
+		if err := r1cs.IsSolved(witness(0, 0, 5)); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+			t.Fatal(curveID.String(), "expected unsatisfied constraint error")
+		}
+	}
+}
+
+type lookupNCircuit struct {
+	Sel      Variable
+	B        [3]Variable
+	X        [8]Variable
+	Expected Variable `gnark:",public"`
+	table    int      // 0: no lookup, 1: constant table, 2: variable table, 3: half constant table
+	rawBits  bool     // if set, the selector bits are B (not asserted to be boolean) instead of the bits of Sel
+}
+
+func (c *lookupNCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	b := c.B[:]
+	if !c.rawBits {
+		b = cs.ToBinary(c.Sel, 3)
+	}
+	values := make([]interface{}, len(c.X))
+	for i := 0; i < len(values); i++ {
+		switch {
+		case c.table == 1 || (c.table == 3 && i < 4):
+			values[i] = 100 + i
+		case c.table == 2 || c.table == 3:
+			values[i] = c.X[i]
+		}
+	}
+	if c.table != 0 {
+		cs.AssertIsEqual(cs.LookupN(b, values...), c.Expected)
+	}
+	return nil
+}
+
+func TestLookupNConstraints(t *testing.T) {
+	nbConstraints := func(table int, rawBits bool) int {
+		t.Helper()
+		r1cs, err := Compile(gurvy.BN256, &lookupNCircuit{table: table, rawBits: rawBits})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(r1cs.GetNbConstraints())
+	}
+
+	// the lookup itself, without the decomposition of the selector and the final assertion
+	base := nbConstraints(0, false) + 1
+	if n := nbConstraints(1, false) - base; n != 8/4 {
+		t.Fatal("unexpected number of constraints for a constant table", n)
+	}
+	if n := nbConstraints(2, false) - base; n != 8-1 {
+		t.Fatal("unexpected number of constraints for a variable table", n)
+	}
+	if n := nbConstraints(3, false) - base; n != 1+3+1 {
+		t.Fatal("unexpected number of constraints for a half constant table", n)
+	}
+
+	// bits which are not known to be boolean are asserted once, whatever the table
+	base = nbConstraints(0, true) + 1
+	if n := nbConstraints(3, true) - base; n != 3+1+3+1 {
+		t.Fatal("unexpected number of constraints for a half constant table with raw bits", n)
+	}
+	if n := nbConstraints(2, true) - base; n != 3+8-1 {
+		t.Fatal("unexpected number of constraints for a variable table with raw bits", n)
+	}
+
+	r1cs, err := Compile(gurvy.BN256, &lookupNCircuit{table: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sel := 0; sel < 8; sel++ {
+		witness := map[string]interface{}{"Sel": sel, "Expected": 100 + sel, "B_0": 0, "B_1": 0, "B_2": 0}
+		for i := 0; i < 8; i++ {
+			witness[fmt.Sprintf("X_%d", i)] = 0
+		}
+		if err := r1cs.IsSolved(witness); err != nil {
+			t.Fatal(sel, err)
+		}
+		witness["Expected"] = 101 + sel
+		if err := r1cs.IsSolved(witness); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+			t.Fatal(sel, "expected unsatisfied constraint error")
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Test the integer division against big.Int by solving the constraint system on all curves
+
+type divModCircuit struct {
+	A, B       Variable
+	Q, R       Variable `gnark:",public"`
+	QCst, RCst Variable `gnark:",public"`
+}
+
+func (c *divModCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	const nbBits = 64
+	q, r := cs.DivMod(c.A, c.B, nbBits)
+	cs.AssertIsEqual(q, c.Q)
+	cs.AssertIsEqual(r, c.R)
+
+	q, r = cs.DivMod(c.A, 1000, nbBits)
+	cs.AssertIsEqual(q, c.QCst)
+	cs.AssertIsEqual(r, c.RCst)
+	return nil
+}
+
+func TestDivMod(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	rng := rand.New(rand.NewSource(42))
+	testData := [][2]*big.Int{
+		{big.NewInt(0), big.NewInt(1)},
+		{big.NewInt(95), big.NewInt(7)},
+		{big.NewInt(6), big.NewInt(7)},
+		{new(big.Int).SetUint64(^uint64(0)), new(big.Int).SetUint64(^uint64(0))},
+		{new(big.Int).SetUint64(^uint64(0)), big.NewInt(1)},
+	}
+	for i := 0; i < 10; i++ {
+		testData = append(testData, [2]*big.Int{
+			new(big.Int).SetUint64(rng.Uint64()),
+			new(big.Int).SetUint64(rng.Uint64() >> uint(rng.Intn(64))),
+		})
+	}
+
+	for _, curveID := range curves {
+		var circuit divModCircuit
+		r1cs, err := Compile(curveID, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		witness := func(a, b, q, r *big.Int) map[string]interface{} {
+			var qCst, rCst big.Int
+			qCst.DivMod(a, big.NewInt(1000), &rCst)
+			return map[string]interface{}{
+				"A":    a,
+				"B":    b,
+				"Q":    q,
+				"R":    r,
+				"QCst": &qCst,
+				"RCst": &rCst,
+			}
+		}
+
+		for _, d := range testData {
+			a, b := d[0], d[1]
+			if b.Sign() == 0 {
+				b.SetUint64(1)
+			}
+			var q, r big.Int
+			q.DivMod(a, b, &r)
+
+			if err := r1cs.IsSolved(witness(a, b, &q, &r)); err != nil {
+				t.Fatal(curveID.String(), a, b, err)
+			}
+
+			// a = (q-1)*b + (r+b) holds, but r+b >= b
+			var q1, r1 big.Int
+			q1.Sub(&q, big.NewInt(1))
+			r1.Add(&r, b)
+			if err := r1cs.IsSolved(witness(a, b, &q1, &r1)); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+				t.Fatal(curveID.String(), a, b, "expected unsatisfied constraint error")
+			}
+		}
+
+		// a doesn't fit in 64 bits
+		a := new(big.Int).Lsh(big.NewInt(1), 64)
+		if err := r1cs.IsSolved(witness(a, big.NewInt(2), new(big.Int).Rsh(a, 1), big.NewInt(0))); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+			t.Fatal(curveID.String(), "expected unsatisfied constraint error")
+		}
+
+		// division by zero
+		if err := r1cs.IsSolved(witness(big.NewInt(1), big.NewInt(0), big.NewInt(0), big.NewInt(0))); err == nil {
+			t.Fatal(curveID.String(), "division by zero should fail")
+		}
+	}
+}
+
+type divModUncheckedCircuit struct {
+	A, B Variable
+}
+
+func (c *divModUncheckedCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.DivMod(c.A, c.B, 64)
+	return nil
+}
+
+// dishonest hints computing q+k or r+k*b instead of the quotient q and the remainder r of a by b
+// (hint functions are identified by their name, closures can't be used)
+func intDivMinusOne(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	return addMultiple(hint.IntDiv, modulus, inputs, result, -1, big.NewInt(1))
+}
+
+func intDivPlusOne(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	return addMultiple(hint.IntDiv, modulus, inputs, result, 1, big.NewInt(1))
+}
+
+func intModPlusB(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	return addMultiple(hint.IntMod, modulus, inputs, result, 1, inputs[1])
+}
+
+func intModMinusB(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	return addMultiple(hint.IntMod, modulus, inputs, result, -1, inputs[1])
+}
+
+// addMultiple sets result = f(inputs) + k*x mod modulus
+func addMultiple(f hint.Function, modulus *big.Int, inputs []*big.Int, result *big.Int, k int64, x *big.Int) error {
+	if err := f(modulus, inputs, result); err != nil {
+		return err
+	}
+	var d big.Int
+	d.Mul(big.NewInt(k), x)
+	result.Add(result, &d).Mod(result, modulus)
+	return nil
+}
+
+func TestDivModDishonestHints(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	var circuit divModUncheckedCircuit
+	res, err := Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untyped := res.(*backend_r1cs.UntypedR1CS)
+
+	// the hints computing q and r are replaced by dishonest ones: only the assertions of DivMod
+	// (a = q*b + r, r < b and the range checks) can reject their results
+	testData := []struct {
+		name   string
+		q, r   hint.Function
+		honest bool
+	}{
+		{"honest", hint.IntDiv, hint.IntMod, true},
+		{"q-1, r+b", intDivMinusOne, intModPlusB, false},
+		{"q+1, r-b", intDivPlusOne, intModMinusB, false},
+		{"q+1, r", intDivPlusOne, hint.IntMod, false},
+		{"q, r+b", hint.IntDiv, intModPlusB, false},
+	}
+
+	for _, d := range testData {
+		dishonest := *untyped
+		dishonest.Hints = make([]r1c.Hint, len(untyped.Hints))
+		for i, h := range untyped.Hints {
+			switch h.ID {
+			case hint.UUID(hint.IntDiv):
+				h.ID = hint.Register(d.q)
+			case hint.UUID(hint.IntMod):
+				h.ID = hint.Register(d.r)
+			}
+			dishonest.Hints[i] = h
+		}
+
+		for _, curveID := range curves {
+			r1cs := dishonest.ToR1CS(curveID)
+			for _, ab := range [][2]int{{95, 7}, {6, 7}, {7, 7}, {1 << 40, 3}} {
+				err := r1cs.IsSolved(map[string]interface{}{"A": ab[0], "B": ab[1]})
+				if d.honest && err != nil {
+					t.Fatal(d.name, curveID.String(), ab, err)
+				}
+				if !d.honest && !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+					t.Fatal(d.name, curveID.String(), ab, "expected unsatisfied constraint error, got", err)
+				}
+			}
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Test the range assertions by solving the constraint system on all curves
+
+type rangeCircuit struct {
+	A, B, C Variable
+}
+
+func (c *rangeCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsLess(c.A, c.B)
+	cs.AssertIsLess(c.A, 100)
+	cs.AssertIsInRange(c.A, 10, 99)
+	cs.AssertIsInRange(c.B, c.A, 1000)
+	cs.RangeCheck(c.A, 7)
+	cs.RangeCheck(c.B, 10)
+	cs.RangeCheck(c.A, 8)
+	cs.RangeCheck(c.C, 16)
+	cs.ToBinary(c.C, 20)
+	return nil
+}
+
+func TestRangeAssertions(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	// a, b, c, expected error (empty if the witness is valid)
+	testData := []struct {
+		a, b, c int
+		err     string
+	}{
+		{10, 11, 0, ""},
+		{99, 1000, 65535, ""},
+		{42, 42, 1, " < "},
+		{100, 200, 1, " < 100"},
+		{9, 200, 1, "10 <= "},
+		{50, 1001, 1, " <= 1000"},
+		{50, 1000, 65536, " < 2^16"},
+	}
+
+	for _, curveID := range curves {
+		var circuit rangeCircuit
+		r1cs, err := Compile(curveID, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, d := range testData {
+			witness := map[string]interface{}{
+				"A": d.a,
+				"B": d.b,
+				"C": d.c,
+			}
+			err := r1cs.IsSolved(witness)
+			if d.err == "" {
+				if err != nil {
+					t.Fatal(curveID.String(), d, err)
+				}
+				continue
+			}
+			if !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+				t.Fatal(curveID.String(), d, "expected unsatisfied constraint error")
+			}
+			if !strings.Contains(err.Error(), d.err) {
+				t.Fatal(curveID.String(), d, "unexpected debug info:", err)
+			}
+		}
+	}
+}
+
+type rangeBitsCircuit struct {
+	A         Variable
+	rangeOnly bool
+}
+
+func (c *rangeBitsCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	if c.rangeOnly {
+		cs.RangeCheck(c.A, 8)
+	} else {
+		cs.AssertIsLess(c.A, 100)
+	}
+	return nil
+}
+
+// ithBitDoubled is a dishonest hint returning 2 for the bits set to 1
+func ithBitDoubled(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if err := hint.IthBit(modulus, inputs, result); err != nil {
+		return err
+	}
+	result.Lsh(result, 1)
+	return nil
+}
+
+// the bits computed on behalf of a range assertion must fail with the debug info of the assertion
+func TestRangeAssertionsBitsDebugInfo(t *testing.T) {
+	curves := []gurvy.ID{gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761}
+
+	for _, rangeOnly := range []bool{true, false} {
+		circuit := rangeBitsCircuit{rangeOnly: rangeOnly}
+		res, err := Compile(gurvy.UNKNOWN, &circuit, WithUntypedR1CS())
+		if err != nil {
+			t.Fatal(err)
+		}
+		untyped := res.(*backend_r1cs.UntypedR1CS)
+
+		dishonest := *untyped
+		dishonest.Hints = make([]r1c.Hint, len(untyped.Hints))
+		for i, h := range untyped.Hints {
+			if h.ID == hint.UUID(hint.IthBit) {
+				h.ID = hint.Register(ithBitDoubled)
+			}
+			dishonest.Hints[i] = h
+		}
+
+		expected := " < 100"
+		if rangeOnly {
+			expected = " < 2^8"
+		}
+		for _, curveID := range curves {
+			err := dishonest.ToR1CS(curveID).IsSolved(map[string]interface{}{"A": 5})
+			if !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+				t.Fatal(curveID.String(), rangeOnly, "expected unsatisfied constraint error, got", err)
+			}
+			if !strings.Contains(err.Error(), expected) || strings.Contains(err.Error(), "AssertIsBoolean") {
+				t.Fatal(curveID.String(), rangeOnly, "unexpected debug info:", err)
+			}
+		}
+	}
+}
+
+type rangeCheckBatchCircuit struct {
+	A, B Variable
+	n    int
+}
+
+func (c *rangeCheckBatchCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	for i := 0; i < c.n; i++ {
+		cs.RangeCheck(c.A, 8+i)
+		cs.RangeCheck(cs.Add(c.B, c.A), 8)
+		cs.RangeCheck(cs.Add(c.A, c.B), 16)
+	}
+	return nil
+}
+
+func TestRangeCheckBatch(t *testing.T) {
+	// the same variables checked several times are decomposed once
+	r1cs1, err := Compile(gurvy.BN256, &rangeCheckBatchCircuit{n: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1cs10, err := Compile(gurvy.BN256, &rangeCheckBatchCircuit{n: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1cs1.GetNbConstraints() != r1cs10.GetNbConstraints() {
+		t.Fatal("range checks should be batched", r1cs1.GetNbConstraints(), r1cs10.GetNbConstraints())
+	}
+	if r1cs1.GetNbConstraints() != 2*8 {
+		t.Fatal("unexpected number of constraints", r1cs1.GetNbConstraints())
+	}
+}
+
+type rangeCheckManyCircuit struct {
+	X []Variable
+}
+
+func (c *rangeCheckManyCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	for i := 0; i < len(c.X); i++ {
+		cs.RangeCheck(c.X[i], 8)
+	}
+	return nil
+}
+
+func TestRangeCheckMany(t *testing.T) {
+	// each distinct variable costs nbBits assertions
+	const n = 10
+	r1cs, err := Compile(gurvy.BN256, &rangeCheckManyCircuit{X: make([]Variable, n)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1cs.GetNbConstraints() != n*8 {
+		t.Fatal("unexpected number of constraints", r1cs.GetNbConstraints())
+	}
+
+	witness := make(map[string]interface{})
+	for i := 0; i < n; i++ {
+		witness[fmt.Sprintf("X_%d", i)] = 255 - i
+	}
+	if err := r1cs.IsSolved(witness); err != nil {
+		t.Fatal(err)
+	}
+	witness["X_3"] = 256
+	if err := r1cs.IsSolved(witness); !errors.Is(err, backend.ErrUnsatisfiedConstraint) || !strings.Contains(err.Error(), " < 2^8") {
+		t.Fatal("expected unsatisfied constraint error, got", err)
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Test the components boundaries
+
+type squareComponent struct {
+	X, Square Variable
+}
+
+func (c *squareComponent) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	c.Square = cs.Mul(c.X, c.X)
+	return nil
+}
+
+type sumOfSquaresComponent struct {
+	X, Y, Expected Variable
+}
+
+func (c *sumOfSquaresComponent) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	x2 := squareComponent{X: c.X}
+	if err := cs.Define("square", &x2); err != nil {
+		return err
+	}
+	y2 := squareComponent{X: c.Y}
+	if err := cs.Define("square", &y2); err != nil {
+		return err
+	}
+	cs.AssertIsEqual(cs.Add(x2.Square, y2.Square), c.Expected)
+	return nil
+}
+
+type componentCircuit struct {
+	X, Y Variable
+	Z    Variable `gnark:",public"`
+}
+
+func (c *componentCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(c.X, cs.Mul(c.Y, 1))
+	return cs.Define("sumOfSquares", &sumOfSquaresComponent{X: c.X, Y: c.Y, Expected: c.Z})
+}
+
+func TestComponents(t *testing.T) {
+	r1cs, err := Compile(gurvy.BN256, &componentCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r1cs.IsSolved(map[string]interface{}{"X": 3, "Y": 3, "Z": 18}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failing assertion is reported with the component path
+	err = r1cs.IsSolved(map[string]interface{}{"X": 3, "Y": 3, "Z": 19})
+	if !errors.Is(err, backend.ErrUnsatisfiedConstraint) || !strings.HasSuffix(err.Error(), "in component sumOfSquares") {
+		t.Fatal("expected unsatisfied constraint error in component sumOfSquares, got", err)
+	}
+
+	// the assertion outside of the components has no path
+	err = r1cs.IsSolved(map[string]interface{}{"X": 3, "Y": 4, "Z": 25})
+	if !errors.Is(err, backend.ErrUnsatisfiedConstraint) || strings.Contains(err.Error(), "in component") {
+		t.Fatal("expected unsatisfied constraint error outside of the components, got", err)
+	}
+}
+
+type rangeCheckComponent struct {
+	X, Y Variable
+}
+
+func (c *rangeCheckComponent) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.RangeCheck(c.X, 4)
+	c.Y = c.X
+	return nil
+}
+
+type rangeCheckComponentCircuit struct {
+	X, Y Variable
+}
+
+func (c *rangeCheckComponentCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.RangeCheck(c.Y, 4)
+	return cs.Define("check", &rangeCheckComponent{X: c.X})
+}
+
+func TestComponentRangeCheck(t *testing.T) {
+	r1cs, err := Compile(gurvy.BN256, &rangeCheckComponentCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the range check requested in the component is added in its boundaries
+	stats := backend_r1cs.GetComponentStats(r1cs)
+	expected := []backend_r1cs.ComponentStats{
+		{Path: "check", NbInstances: 1, NbCOConstraints: 0, NbAssertions: 4},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatal("unexpected component stats", stats)
+	}
+
+	err = r1cs.IsSolved(map[string]interface{}{"X": 16, "Y": 0})
+	if !errors.Is(err, backend.ErrUnsatisfiedConstraint) || !strings.HasSuffix(err.Error(), "in component check") {
+		t.Fatal("expected unsatisfied constraint error in component check, got", err)
+	}
+	err = r1cs.IsSolved(map[string]interface{}{"X": 0, "Y": 16})
+	if !errors.Is(err, backend.ErrUnsatisfiedConstraint) || strings.Contains(err.Error(), "in component") {
+		t.Fatal("expected unsatisfied constraint error outside of the components, got", err)
+	}
+}
+
+type forgetfulComponent struct {
+	X, Y Variable
+}
+
+func (c *forgetfulComponent) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.Mul(c.X, c.X)
+	return nil
+}
+
+type forgetfulCircuit struct {
+	X Variable
+}
+
+func (c *forgetfulCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	return cs.Define("forgetful", &forgetfulComponent{X: c.X})
+}
+
+func TestComponentUnsetOutput(t *testing.T) {
+	_, err := Compile(gurvy.BN256, &forgetfulCircuit{})
+	if !errors.Is(err, backend.ErrInputNotSet) || !strings.Contains(err.Error(), "forgetful") {
+		t.Fatal("expected input not set error in component forgetful, got", err)
+	}
+}
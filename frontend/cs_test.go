@@ -1,13 +1,20 @@
 package frontend
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+	"github.com/consensys/gurvy"
 )
 
 func TestReduce(t *testing.T) {
 
-	cs := newConstraintSystem()
+	cs := newConstraintSystem(0)
 	x := cs.newInternalVariable()
 	y := cs.newInternalVariable()
 	z := cs.newInternalVariable()
@@ -31,3 +38,265 @@ func TestReduce(t *testing.T) {
 		fmt.Println(cs.coeffs[t.CoeffID()])
 	}
 }
+
+type optimizeCircuit struct {
+	X, Y Variable
+	Z    Variable `gnark:",public"`
+}
+
+func (c *optimizeCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	a := cs.Div(c.X, 4)                         // linear, removed
+	b := cs.Mul(c.X, c.Y)                       // kept
+	b2 := cs.Mul(c.Y, c.X)                      // same product, removed
+	k := cs.Mul(cs.Constant(3), cs.Constant(5)) // constant, removed
+	cs.AssertIsEqual(k, 15)                     // holds on constants, removed
+	cs.AssertIsEqual(b, b2)                     // b2 is substituted by b, removed
+	cs.Println("a:", a)
+	cs.AssertIsEqual(cs.Add(a, b, b2, k), c.Z)
+	cs.AssertIsEqual(cs.Add(a, b, b2, k), c.Z) // duplicate, removed
+	return nil
+}
+
+func TestOptimize(t *testing.T) {
+	var circuit optimizeCircuit
+	untyped, err := Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := untyped.(*r1cs.UntypedR1CS).Optimize(gurvy.BN256)
+	expected := r1cs.OptimizationStats{
+		NbConstraintsBefore:   8,
+		NbConstraintsAfter:    2,
+		NbCOConstraintsBefore: 4,
+		NbCOConstraintsAfter:  1,
+	}
+	if stats != expected {
+		t.Fatal("unexpected optimization stats", stats)
+	}
+
+	optimized := untyped.(*r1cs.UntypedR1CS).ToR1CS(gurvy.BN256)
+	if err := optimized.IsSolved(map[string]interface{}{"X": 8, "Y": 3, "Z": 2 + 24 + 24 + 15}); err != nil {
+		t.Fatal(err)
+	}
+	if err := optimized.IsSolved(map[string]interface{}{"X": 8, "Y": 3, "Z": 64}); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error")
+	}
+}
+
+type linearAllocationsCircuit struct {
+	X, Y Variable
+	Z    Variable `gnark:",public"`
+}
+
+func (c *linearAllocationsCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	s1 := cs.Add(c.X, c.Y)
+	s2 := cs.Add(c.Y, c.X)
+	cs.Println(s1, s2)                     // allocates X + Y twice, removed
+	cs.AssertIsEqual(cs.Mul(s1, c.X), c.Z) // kept
+	cs.AssertIsEqual(cs.Mul(c.X, s2), c.Z) // same product, removed, and duplicate assertion, removed
+	return nil
+}
+
+func TestOptimizeLinearAllocations(t *testing.T) {
+	var circuit linearAllocationsCircuit
+	untyped, err := Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := untyped.(*r1cs.UntypedR1CS).Optimize(gurvy.BN256)
+	expected := r1cs.OptimizationStats{
+		NbConstraintsBefore:   6,
+		NbConstraintsAfter:    2,
+		NbCOConstraintsBefore: 4,
+		NbCOConstraintsAfter:  1,
+	}
+	if stats != expected {
+		t.Fatal("unexpected optimization stats", stats)
+	}
+
+	optimized := untyped.(*r1cs.UntypedR1CS).ToR1CS(gurvy.BN256)
+	if err := optimized.IsSolved(map[string]interface{}{"X": 3, "Y": 4, "Z": 21}); err != nil {
+		t.Fatal(err)
+	}
+	if err := optimized.IsSolved(map[string]interface{}{"X": 3, "Y": 4, "Z": 22}); !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error")
+	}
+}
+
+func TestCompileOptions(t *testing.T) {
+	var circuit optimizeCircuit
+	res, err := Compile(gurvy.BN256, &circuit, WithCapacity(16), WithOptimizations(true), WithUntypedR1CS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	untyped, ok := res.(*r1cs.UntypedR1CS)
+	if !ok {
+		t.Fatal("expected an untyped R1CS")
+	}
+	if untyped.NbConstraints != 2 || len(untyped.DebugInfo) != 1 || len(untyped.Logs) != 1 {
+		t.Fatal("expected an optimized R1CS with debug info")
+	}
+
+	circuit = optimizeCircuit{}
+	res, err = Compile(gurvy.BN256, &circuit, WithoutDebugInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetNbConstraints() != 8 {
+		t.Fatal("expected a non optimized R1CS, got", res.GetNbConstraints(), "constraints")
+	}
+	err = res.IsSolved(map[string]interface{}{"X": 8, "Y": 3, "Z": 64})
+	if !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected unsatisfied constraint error")
+	}
+	if !strings.Contains(err.Error(), "assertion, constraint #") {
+		t.Fatal("expected the failing assertion index in the error, got", err)
+	}
+}
+
+type callStackCircuit struct {
+	X Variable
+}
+
+func (c *callStackCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.ToBinary(c.X, 2)
+	cs.AssertIsEqual(c.X, 1)
+	return nil
+}
+
+func TestTermOverflow(t *testing.T) {
+
+	// without wide terms, a wire ID which doesn't fit is recorded as an error instead of panicking
+	cs := newConstraintSystem(0)
+	cs.packTerm(r1c.MaxWires, 1, backend.Internal)
+	if !errors.Is(cs.termErr, r1c.ErrTermOverflow) {
+		t.Fatal("expected ErrTermOverflow, got", cs.termErr)
+	}
+
+	// with wide terms, only the terms which need it are widened
+	cs = newConstraintSystem(0)
+	cs.wideTerms = true
+	if term := cs.packTerm(r1c.MaxWires-1, 1, backend.Internal); term.IsWide() {
+		t.Fatal("a term whose wire ID fits must not be widened")
+	}
+	term := cs.packTerm(r1c.MaxWires, 1, backend.Internal)
+	if cs.termErr != nil {
+		t.Fatal(cs.termErr)
+	}
+	if !term.IsWide() || term.VariableID() != r1c.MaxWires || term.CoeffID() != 1 {
+		t.Fatalf("unexpected wide term %#x", uint64(term))
+	}
+
+	// a coefficient ID which doesn't fit is an error, wide terms or not
+	cs.packTerm(0, r1c.MaxCoefficients, backend.Internal)
+	if !errors.Is(cs.termErr, r1c.ErrTermOverflow) {
+		t.Fatal("expected ErrTermOverflow, got", cs.termErr)
+	}
+}
+
+func TestUnsatisfiedConstraintError(t *testing.T) {
+	var circuit callStackCircuit
+	res, err := Compile(gurvy.BN256, &circuit, WithCallStacks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkStack := func(stack []string) {
+		t.Helper()
+		if len(stack) == 0 || !strings.Contains(stack[len(stack)-1], "callStackCircuit).Define") || !strings.Contains(stack[len(stack)-1], "cs_test.go") {
+			t.Fatal("expected the call stack to end in the circuit Define, got", stack)
+		}
+	}
+
+	// 5 doesn't fit on 2 bits, the binary decomposition fails
+	var unsatisfied *backend.UnsatisfiedConstraintError
+	err = res.IsSolved(map[string]interface{}{"X": 5})
+	if !errors.As(err, &unsatisfied) || !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected an UnsatisfiedConstraintError, got", err)
+	}
+	if unsatisfied.Assertion || unsatisfied.ConstraintID >= int(res.GetNbConstraints())-1 {
+		t.Fatal("expected a computational constraint to fail, got", unsatisfied.ConstraintID)
+	}
+	checkStack(unsatisfied.Stack)
+
+	// 2 fits on 2 bits, but 2 != 1
+	err = res.IsSolved(map[string]interface{}{"X": 2})
+	if !errors.As(err, &unsatisfied) || !unsatisfied.Assertion {
+		t.Fatal("expected a failing assertion, got", err)
+	}
+	if unsatisfied.ConstraintID != int(res.GetNbConstraints())-1 {
+		t.Fatal("unexpected constraint index", unsatisfied.ConstraintID)
+	}
+	checkStack(unsatisfied.Stack)
+	found := false
+	for _, v := range unsatisfied.Wires {
+		found = found || v == "2"
+	}
+	if !found || !strings.Contains(err.Error(), "cs_test.go") {
+		t.Fatal("expected the wire values and the stack in the error, got", err)
+	}
+
+	// computational constraints have no stack by default
+	circuit = callStackCircuit{}
+	res, err = Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = res.IsSolved(map[string]interface{}{"X": 5})
+	if !errors.As(err, &unsatisfied) || len(unsatisfied.Stack) != 0 {
+		t.Fatal("expected no call stack, got", err)
+	}
+}
+
+type solverOrderCircuit struct {
+	X, Z Variable
+}
+
+func (c *solverOrderCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	// a chain of dependent constraints, the last one comes early in the constraints but late in the dependency levels
+	y := c.X
+	for i := 0; i < 3; i++ {
+		y = cs.Mul(y, y)
+	}
+	cs.ToBinary(y, 9)
+
+	// independent constraints, which are all in the first dependency level
+	for i := 0; i < 100; i++ {
+		cs.ToBinary(c.Z, 2)
+	}
+	return nil
+}
+
+func TestSolverOrder(t *testing.T) {
+	var circuit solverOrderCircuit
+	res, err := Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := res.IsSolved(map[string]interface{}{"X": 2, "Z": 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// both 3^8 and 4 are out of range, the first failing constraint in the sequential order is reported
+	var unsatisfied *backend.UnsatisfiedConstraintError
+	err = res.IsSolved(map[string]interface{}{"X": 3, "Z": 4})
+	if !errors.As(err, &unsatisfied) || unsatisfied.Assertion {
+		t.Fatal("expected a computational constraint to fail, got", err)
+	}
+	found := false
+	for _, v := range unsatisfied.Wires {
+		found = found || v == "6561"
+	}
+	if !found {
+		t.Fatal("expected the binary decomposition of 3^8 to fail, got", err)
+	}
+
+	// only the independent constraints fail
+	err = res.IsSolved(map[string]interface{}{"X": 2, "Z": 4})
+	if !errors.As(err, &unsatisfied) || unsatisfied.ConstraintID != 4 {
+		t.Fatal("expected the first binary decomposition of Z to fail, got", err)
+	}
+}
@@ -0,0 +1,399 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/r1cs"
+	"github.com/consensys/gnark/backend/r1cs/r1c"
+	"github.com/consensys/gurvy"
+)
+
+// IsSolved executes circuit.Define on the values of the witness (see ParseWitness), modulo the scalar field
+// of curveID, and returns nil if all the constraints hold.
+//
+// Unlike Compile followed by R1CS.Solve, no R1CS is built: each constraint is solved and checked (and each hint
+// computed) as soon as it is added, so a failing assertion returns a *backend.UnsatisfiedConstraintError
+// whose Stack points to the line of Define that added it. This makes it a fast way to unit test a gadget;
+// it does not replace a proof (see groth16.Assert) to test the circuit is correctly constrained.
+//
+// In the error, the ConstraintID of an assertion is its index among the assertions and the wire IDs follow
+// the layout [internal | secret | public] of the wires allocated when the execution stopped
+func IsSolved(circuit Circuit, witness interface{}, curveID gurvy.ID) (err error) {
+	if curveID == gurvy.UNKNOWN {
+		return errors.New("a curve is needed to execute the circuit")
+	}
+
+	assignment, err := ParseWitness(witness)
+	if err != nil {
+		return err
+	}
+
+	cs := newConstraintSystem(0)
+	cs.curveID = curveID
+	cs.engine = &engine{
+		modulus: r1cs.FieldModulus(curveID),
+		public:  []*big.Int{big.NewInt(1)}, // ONE wire
+	}
+
+	// the inputs are allocated as in Compile, with their values. circuit may be the witness itself
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		if !tInput.CanSet() {
+			return errors.New("can't set val " + name)
+		}
+		val, ok := assignment[name]
+		if !ok {
+			return fmt.Errorf("%q: %w", name, backend.ErrInputNotSet)
+		}
+		v := backend.FromInterface(val)
+		v.Mod(&v, cs.engine.modulus)
+
+		switch visibility {
+		case backend.Unset, backend.Secret:
+			tInput.Set(reflect.ValueOf(cs.newSecretVariable(name)))
+			cs.engine.secret = append(cs.engine.secret, &v)
+		case backend.Public:
+			tInput.Set(reflect.ValueOf(cs.newPublicVariable(name)))
+			cs.engine.public = append(cs.engine.public, &v)
+		}
+		return nil
+	}
+	if err := parseType(circuit, "", backend.Unset, handler); err != nil {
+		return err
+	}
+
+	// the API has no error to return, a failing constraint aborts Define
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(engineError)
+			if !ok {
+				panic(r)
+			}
+			err = e.err
+		}
+	}()
+
+	if err := circuit.Define(curveID, &cs); err != nil {
+		return err
+	}
+	cs.flushRangeChecks()
+
+	if len(cs.engine.pending) != 0 {
+		return fmt.Errorf("assertion #%d depends on wires which are never computed", cs.engine.pending[0].id)
+	}
+
+	return nil
+}
+
+// engine holds the values of the wires while IsSolved executes a circuit
+type engine struct {
+	modulus  *big.Int
+	internal []*big.Int         // values of the internal wires, nil if not computed yet
+	secret   []*big.Int         // values of the secret inputs
+	public   []*big.Int         // values of the public inputs (the ONE wire first)
+	pending  []pendingAssertion // assertions on wires not computed yet (for example the bits of ToBinary)
+}
+
+// pendingAssertion is an assertion checked as soon as its wires are computed
+type pendingAssertion struct {
+	id         int
+	constraint r1c.R1C
+	debugInfo  logEntry
+	component  string
+}
+
+// engineError aborts the execution of Define (see IsSolved)
+type engineError struct {
+	err error
+}
+
+func (e *engine) wires(visibility backend.Visibility) *[]*big.Int {
+	switch visibility {
+	case backend.Internal:
+		return &e.internal
+	case backend.Secret:
+		return &e.secret
+	case backend.Public:
+		return &e.public
+	default:
+		return nil
+	}
+}
+
+// value returns the value of the wire of t, nil if it is not computed
+func (e *engine) value(t r1c.Term) *big.Int {
+	_, _, id, visibility := t.Unpack()
+	wires := e.wires(visibility)
+	if wires == nil || id >= len(*wires) {
+		return nil
+	}
+	return (*wires)[id]
+}
+
+func (e *engine) setValue(t r1c.Term, v *big.Int) {
+	_, _, id, visibility := t.Unpack()
+	wires := e.wires(visibility)
+	for len(*wires) <= id {
+		*wires = append(*wires, nil)
+	}
+	(*wires)[id] = v
+}
+
+// abort stops the execution of Define with err
+func (cs *ConstraintSystem) abort(err error) {
+	panic(engineError{err})
+}
+
+// checkInputsSet aborts the execution if a Variable used in the circuit is unset
+func (cs *ConstraintSystem) checkInputsSet() {
+	if len(cs.unsetVariables) != 0 {
+		cs.abort(fmt.Errorf("%w: %s", backend.ErrInputNotSet, cs.unsetVariables[0].format))
+	}
+}
+
+// evaluate returns the value of linExp, and false if one of its wires is not computed
+func (cs *ConstraintSystem) evaluate(linExp r1c.LinearExpression) (*big.Int, bool) {
+	var res, tmp big.Int
+	for _, t := range linExp {
+		v := cs.engine.value(t)
+		if v == nil {
+			return nil, false
+		}
+		tmp.Mul(&cs.coeffs[t.CoeffID()], v)
+		res.Add(&res, &tmp)
+	}
+	res.Mod(&res, cs.engine.modulus)
+	return &res, true
+}
+
+// executeConstraint computes the wire of a computational constraint and returns an error if the constraint doesn't hold
+func (cs *ConstraintSystem) executeConstraint(r r1c.R1C) *backend.UnsatisfiedConstraintError {
+	cs.checkInputsSet()
+	modulus := cs.engine.modulus
+
+	switch r.Solver {
+
+	// the wire which is not computed yet is isolated
+	case r1c.SingleOutput:
+		var loc int
+		var toCompute r1c.Term
+		var values [3]big.Int // L, R, O without the term to compute
+		for i, linExp := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+			for _, t := range linExp {
+				v := cs.engine.value(t)
+				if v == nil {
+					if loc != 0 {
+						cs.abort(errors.New("found more than one wire to instantiate"))
+					}
+					toCompute = t
+					loc = i + 1
+					continue
+				}
+				var tmp big.Int
+				tmp.Mul(&cs.coeffs[t.CoeffID()], v)
+				values[i].Add(&values[i], &tmp)
+			}
+			values[i].Mod(&values[i], modulus)
+		}
+		if loc == 0 {
+			break
+		}
+
+		var res big.Int
+		a, b, c := &values[0], &values[1], &values[2]
+		switch loc {
+		case 1:
+			if b.Sign() != 0 {
+				res.ModInverse(b, modulus).Mul(&res, c).Sub(&res, a)
+			}
+		case 2:
+			if a.Sign() != 0 {
+				res.ModInverse(a, modulus).Mul(&res, c).Sub(&res, b)
+			}
+		case 3:
+			res.Mul(a, b).Sub(&res, c)
+		}
+
+		// the term to compute is coeff * wire
+		var coeff big.Int
+		coeff.Mod(&cs.coeffs[toCompute.CoeffID()], modulus)
+		if coeff.Sign() == 0 {
+			res.SetUint64(0)
+		} else {
+			coeff.ModInverse(&coeff, modulus)
+			res.Mul(&res, &coeff)
+		}
+		res.Mod(&res, modulus)
+		cs.engine.setValue(toCompute, &res)
+
+	// the wires of L are the bits of O, their coefficient is 2^i
+	case r1c.BinaryDec:
+		n, ok := cs.evaluate(r.O)
+		if !ok {
+			cs.abort(errors.New("binary decomposition of a wire which is not instantiated"))
+		}
+		for _, t := range r.L {
+			i := cs.coeffs[t.CoeffID()].BitLen() - 1
+			cs.engine.setValue(t, new(big.Int).SetUint64(uint64(n.Bit(i))))
+		}
+
+	default:
+		panic("unimplemented solving method")
+	}
+
+	if err := cs.checkConstraint(len(cs.constraints)-1, false, r, logEntry{}, cs.componentPath()); err != nil {
+		return err
+	}
+	cs.executePendingAssertions()
+	return nil
+}
+
+// executeAssertion checks the assertion, or defers it until its wires are computed
+func (cs *ConstraintSystem) executeAssertion(r r1c.R1C, debugInfo logEntry) {
+	cs.checkInputsSet()
+	cs.engine.pending = append(cs.engine.pending, pendingAssertion{
+		id:         len(cs.assertions) - 1,
+		constraint: r,
+		debugInfo:  debugInfo,
+		component:  cs.componentPath(),
+	})
+	cs.executePendingAssertions()
+}
+
+// executeHint computes the wire of the hint
+func (cs *ConstraintSystem) executeHint(h r1c.Hint) {
+	cs.checkInputsSet()
+
+	f, ok := hint.Lookup(h.ID)
+	if !ok {
+		cs.abort(fmt.Errorf("%w (id %d)", hint.ErrNotRegistered, h.ID))
+	}
+
+	inputs := make([]*big.Int, len(h.Inputs))
+	for i := 0; i < len(h.Inputs); i++ {
+		if inputs[i], ok = cs.evaluate(h.Inputs[i]); !ok {
+			cs.abort(errors.New("hint input is not instantiated"))
+		}
+	}
+
+	var result big.Int
+	if err := f(cs.engine.modulus, inputs, &result); err != nil {
+		cs.abort(err)
+	}
+	result.Mod(&result, cs.engine.modulus)
+	cs.engine.setValue(r1c.Pack(h.WireID, 0, backend.Internal), &result)
+	cs.executePendingAssertions()
+}
+
+// executePendingAssertions checks the pending assertions whose wires are computed
+func (cs *ConstraintSystem) executePendingAssertions() {
+	pending := cs.engine.pending[:0]
+	for _, p := range cs.engine.pending {
+		if !cs.isComputed(p.constraint) {
+			pending = append(pending, p)
+			continue
+		}
+		if err := cs.checkConstraint(p.id, true, p.constraint, p.debugInfo, p.component); err != nil {
+			cs.abort(err)
+		}
+	}
+	cs.engine.pending = pending
+}
+
+func (cs *ConstraintSystem) isComputed(r r1c.R1C) bool {
+	for _, linExp := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+		for _, t := range linExp {
+			if cs.engine.value(t) == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkConstraint returns an error if L * R != O; the wires of r must be computed
+func (cs *ConstraintSystem) checkConstraint(id int, assertion bool, r r1c.R1C, debugInfo logEntry, component string) *backend.UnsatisfiedConstraintError {
+	a, _ := cs.evaluate(r.L)
+	b, _ := cs.evaluate(r.R)
+	c, _ := cs.evaluate(r.O)
+
+	var ab big.Int
+	ab.Mul(a, b).Mod(&ab, cs.engine.modulus)
+	if ab.Cmp(c) == 0 {
+		return nil
+	}
+
+	err := &backend.UnsatisfiedConstraintError{
+		ConstraintID: id,
+		Assertion:    assertion,
+		L:            a.String(),
+		R:            b.String(),
+		O:            c.String(),
+		Wires:        make(map[int]string),
+		Stack:        debugInfo.stack,
+		Component:    component,
+	}
+	if assertion {
+		err.DebugInfo = cs.resolve(debugInfo)
+	}
+	for _, linExp := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+		for _, t := range linExp {
+			err.Wires[cs.wireID(t)] = cs.engine.value(t).String()
+		}
+	}
+	return err
+}
+
+// resolve returns the log entry formatted with the values of its wires
+func (cs *ConstraintSystem) resolve(entry logEntry) string {
+	toResolve := make([]interface{}, len(entry.toResolve))
+	for i, t := range entry.toResolve {
+		if v := cs.engine.value(t); v != nil {
+			toResolve[i] = v.String()
+		} else {
+			toResolve[i] = "<unsolved>"
+		}
+	}
+	return fmt.Sprintf(entry.format, toResolve...)
+}
+
+// wireID returns the ID of the wire of t in the layout [internal | secret | public] of the wires allocated so far
+func (cs *ConstraintSystem) wireID(t r1c.Term) int {
+	_, _, id, visibility := t.Unpack()
+	switch visibility {
+	case backend.Secret:
+		return id + len(cs.internal.variables)
+	case backend.Public:
+		return id + len(cs.internal.variables) + len(cs.secret.variables)
+	default:
+		return id
+	}
+}
+
+// componentPath returns the path of the component being defined, if any
+func (cs *ConstraintSystem) componentPath() string {
+	if len(cs.componentStack) == 0 {
+		return ""
+	}
+	return r1c.ComponentPath(cs.components, cs.componentStack[len(cs.componentStack)-1])
+}
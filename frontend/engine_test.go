@@ -0,0 +1,76 @@
+package frontend
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gurvy"
+)
+
+type engineCircuit struct {
+	X Variable
+	Y Variable `gnark:",public"`
+}
+
+func (circuit *engineCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	bits := cs.ToBinary(circuit.X, 8)
+	cs.AssertIsEqual(cs.FromBinary(bits...), circuit.X)
+	cs.AssertIsEqual(cs.Mul(circuit.X, cs.Inverse(circuit.X)), 1)
+	q, r := cs.DivMod(circuit.Y, circuit.X, 8)
+	cs.AssertIsEqual(cs.Add(cs.Mul(q, circuit.X), r), circuit.Y)
+	cs.AssertIsLessOrEqual(circuit.X, circuit.Y)
+	return nil
+}
+
+func TestIsSolved(t *testing.T) {
+	var circuit engineCircuit
+	res, err := Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the engine and the solver agree
+	for _, assignment := range []map[string]interface{}{
+		{"X": 42, "Y": 100},
+		{"X": 42, "Y": 42},
+		{"X": 42, "Y": 10},  // X > Y
+		{"X": 300, "Y": 10}, // X doesn't fit on 8 bits
+		{"X": 0, "Y": 10},   // X is not invertible
+	} {
+		expected := res.IsSolved(assignment)
+		err := IsSolved(&engineCircuit{}, assignment, gurvy.BN256)
+		if (err == nil) != (expected == nil) {
+			t.Fatal("the engine and the solver disagree on", assignment, ":", err, expected)
+		}
+		if err != nil && !errors.Is(err, backend.ErrUnsatisfiedConstraint) {
+			t.Fatal("expected an unsatisfied constraint, got", err)
+		}
+	}
+
+	// a failing assertion points to the line of Define which added it
+	var witness engineCircuit
+	witness.X.Assign(42)
+	witness.Y.Assign(10)
+	err = IsSolved(&witness, &witness, gurvy.BN256)
+	var unsatisfied *backend.UnsatisfiedConstraintError
+	if !errors.As(err, &unsatisfied) || !unsatisfied.Assertion {
+		t.Fatal("expected a failing assertion, got", err)
+	}
+	if len(unsatisfied.Stack) == 0 || !strings.Contains(unsatisfied.Stack[len(unsatisfied.Stack)-1], "engine_test.go:23") {
+		t.Fatal("expected the call stack to end in the circuit Define, got", unsatisfied.Stack)
+	}
+
+	// computational constraints have a stack too
+	err = IsSolved(&engineCircuit{}, map[string]interface{}{"X": 300, "Y": 10}, gurvy.BN256)
+	if !errors.As(err, &unsatisfied) || unsatisfied.Assertion || !strings.Contains(err.Error(), "engine_test.go:18") {
+		t.Fatal("expected the binary decomposition to fail, got", err)
+	}
+
+	// missing input
+	err = IsSolved(&engineCircuit{}, map[string]interface{}{"X": 42}, gurvy.BN256)
+	if !errors.Is(err, backend.ErrInputNotSet) {
+		t.Fatal("expected ErrInputNotSet, got", err)
+	}
+}
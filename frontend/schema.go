@@ -0,0 +1,102 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"reflect"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+)
+
+// Schema is a machine-readable description of the inputs of a circuit, for clients
+// building witnesses (it can be encoded in JSON)
+type Schema struct {
+	Inputs []SchemaInput `json:"inputs"`
+}
+
+// SchemaInput describes an input of a circuit
+type SchemaInput struct {
+	Name       string   `json:"name"`           // full name, as expected in a witness (for example "Accounts_0_Nonce")
+	Visibility string   `json:"visibility"`     // "secret" or "public"
+	Path       []string `json:"path,omitempty"` // nesting path in the circuit struct (for example ["Accounts", "0", "Nonce"]), unknown if derived from a R1CS
+	Index      int      `json:"index"`          // position in the witness: secret inputs first, then public inputs, as ordered in the R1CS wires
+	WireID     int      `json:"wireID"`         // id of the wire of the input in the R1CS, -1 if derived from a circuit struct
+}
+
+const (
+	schemaSecret = "secret"
+	schemaPublic = "public"
+)
+
+// NewSchema returns the Schema of the inputs of the circuit, as Compile allocates them
+func NewSchema(circuit Circuit) (Schema, error) {
+	var secret, public []SchemaInput
+
+	p := typeParser{}
+	p.handler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		input := SchemaInput{
+			Name:   name,
+			Path:   append([]string(nil), p.path...),
+			WireID: -1,
+		}
+		switch visibility {
+		case backend.Unset, backend.Secret:
+			input.Visibility = schemaSecret
+			secret = append(secret, input)
+		case backend.Public:
+			input.Visibility = schemaPublic
+			public = append(public, input)
+		}
+		return nil
+	}
+	if err := p.parse(reflect.ValueOf(circuit), "", backend.Unset); err != nil {
+		return Schema{}, err
+	}
+
+	return newSchema(secret, public), nil
+}
+
+// NewSchemaFromR1CS returns the Schema of the inputs of a compiled circuit
+//
+// the nesting paths of the inputs in the circuit struct are not known from the R1CS
+func NewSchemaFromR1CS(r1cs r1cs.R1CS) Schema {
+	secretNames, publicNames := r1cs.GetInputNames()
+	nbWires := int(r1cs.GetNbWires())
+	firstSecret, firstPublic := nbWires-len(publicNames)-len(secretNames), nbWires-len(publicNames)
+
+	var secret, public []SchemaInput
+	for i := 0; i < len(secretNames); i++ {
+		secret = append(secret, SchemaInput{Name: secretNames[i], Visibility: schemaSecret, WireID: firstSecret + i})
+	}
+	for i := 0; i < len(publicNames); i++ {
+		if publicNames[i] == backend.OneWire {
+			continue
+		}
+		public = append(public, SchemaInput{Name: publicNames[i], Visibility: schemaPublic, WireID: firstPublic + i})
+	}
+
+	return newSchema(secret, public)
+}
+
+func newSchema(secret, public []SchemaInput) Schema {
+	res := Schema{Inputs: append(secret, public...)}
+	for i := 0; i < len(res.Inputs); i++ {
+		res.Inputs[i].Index = i
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package frontend
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gurvy"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := NewSchema(&witnessCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SchemaInput{
+		{Name: "Inner_B_0", Visibility: "secret", Path: []string{"Inner", "B", "0"}, Index: 0, WireID: -1},
+		{Name: "Inner_B_1", Visibility: "secret", Path: []string{"Inner", "B", "1"}, Index: 1, WireID: -1},
+		{Name: "Inner_C", Visibility: "secret", Path: []string{"Inner", "C"}, Index: 2, WireID: -1},
+		{Name: "a", Visibility: "public", Path: []string{"a"}, Index: 3, WireID: -1},
+	}
+	if !reflect.DeepEqual(schema.Inputs, expected) {
+		t.Fatal("unexpected schema", schema.Inputs)
+	}
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `{"name":"Inner_B_1","visibility":"secret","path":["Inner","B","1"],"index":1,"wireID":-1}`) {
+		t.Fatal("unexpected JSON encoding", string(encoded))
+	}
+
+	// same inputs from the R1CS, with their wire ids
+	var circuit witnessCircuit
+	r1cs, err := Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromR1CS := NewSchemaFromR1CS(r1cs)
+	if len(fromR1CS.Inputs) != len(expected) {
+		t.Fatal("unexpected schema", fromR1CS.Inputs)
+	}
+	nbWires := int(r1cs.GetNbWires())
+	for i, input := range fromR1CS.Inputs {
+		if input.Name != expected[i].Name || input.Visibility != expected[i].Visibility || input.Index != i {
+			t.Fatal("unexpected input", input)
+		}
+	}
+	// wires are [internal | secret | public], the ONE wire being the first public wire
+	if fromR1CS.Inputs[0].WireID != nbWires-5 || fromR1CS.Inputs[3].WireID != nbWires-1 {
+		t.Fatal("unexpected wire ids", fromR1CS.Inputs)
+	}
+}
@@ -15,7 +15,10 @@ package frontend
 
 import (
 	"fmt"
+	"log"
+	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -87,25 +90,86 @@ const (
 
 type leafHandler func(visibility backend.Visibility, name string, tValue reflect.Value) error
 
+// parseType walks through input (a circuit, typically), calling handler on each Variable it contains.
+// Pointers and interfaces are resolved to their concrete values, and map entries are visited by sorted keys.
+// Leafs that can't be visited (unexported or nil fields, empty slices, ...) are ignored with a warning
+// (see parseTypeStrict)
 func parseType(input interface{}, baseName string, parentVisibility backend.Visibility, handler leafHandler) error {
+	p := typeParser{handler: handler}
+	return p.parse(reflect.ValueOf(input), baseName, parentVisibility)
+}
+
+// parseTypeStrict is like parseType, but returns an error instead of ignoring a leaf
+func parseTypeStrict(input interface{}, baseName string, parentVisibility backend.Visibility, handler leafHandler) error {
+	p := typeParser{handler: handler, strict: true}
+	return p.parse(reflect.ValueOf(input), baseName, parentVisibility)
+}
+
+var (
+	tVariable         = reflect.TypeOf(Variable{})
+	tConstraintSystem = reflect.TypeOf(ConstraintSystem{})
+)
+
+type typeParser struct {
+	handler leafHandler
+	strict  bool
+	path    []string // path of the value being parsed: field names (or tag names), indexes and map keys
+}
+
+// push appends elem to the path, and returns a function restoring it
+func (p *typeParser) push(elem string) func() {
+	if elem == "" {
+		return func() {}
+	}
+	p.path = append(p.path, elem)
+	return func() { p.path = p.path[:len(p.path)-1] }
+}
+
+// Logger prints the warnings about the Variables that Compile and ParseWitness can't reach and ignore
+// (see WithStrictParsing, ParseWitnessStrict). It writes to stdout by default and can be replaced,
+// for example to silence them with log.New(ioutil.Discard, "", 0)
+var Logger = log.New(os.Stdout, "", 0)
+
+// ignore returns an error in strict mode, and logs a warning otherwise (see Logger)
+func (p *typeParser) ignore(name, reason string) error {
+	if p.strict {
+		return fmt.Errorf("%q: %s", name, reason)
+	}
+	Logger.Printf("warning: %q: %s, ignoring", name, reason)
+	return nil
+}
 
-	// types we are lOoutputoking for
-	tVariable := reflect.TypeOf(Variable{})
-	tConstraintSytem := reflect.TypeOf(ConstraintSystem{})
+func (p *typeParser) parse(tValue reflect.Value, baseName string, parentVisibility backend.Visibility) error {
 
-	tValue := reflect.ValueOf(input)
-	if tValue.Kind() == reflect.Ptr {
+	// resolve pointers and interfaces to their concrete values
+	for tValue.Kind() == reflect.Ptr || tValue.Kind() == reflect.Interface {
+		if tValue.IsNil() {
+			if containsVariable(tValue.Type()) {
+				return p.ignore(baseName, "nil pointer")
+			}
+			return nil
+		}
+		if tValue.Kind() == reflect.Interface && tValue.Elem().Kind() != reflect.Ptr {
+			// the concrete value of an interface is not addressable, we parse a copy that we write back
+			return p.parseCopy(tValue.Elem(), baseName, parentVisibility, func(v reflect.Value) error {
+				if !tValue.CanSet() {
+					return p.ignore(baseName, "interface value is not settable")
+				}
+				tValue.Set(v)
+				return nil
+			})
+		}
 		tValue = tValue.Elem()
 	}
 
-	// we either have a pointer, a struct, or a slice / array
-	// and recursively parse members / elements until we find a constraint to allOoutputcate in the circuit.
+	// we either have a struct, a slice / array or a map
+	// and recursively parse members / elements until we find a Variable
 	switch tValue.Kind() {
 	case reflect.Struct:
 		switch tValue.Type() {
 		case tVariable:
-			return handler(parentVisibility, baseName, tValue)
-		case tConstraintSytem:
+			return p.handler(parentVisibility, baseName, tValue)
+		case tConstraintSystem:
 			return nil
 		default:
 			for i := 0; i < tValue.NumField(); i++ {
@@ -142,45 +206,124 @@ func parseType(input interface{}, baseName string, parentVisibility backend.Visi
 
 				fullName := appendName(baseName, name)
 
-				f := tValue.FieldByName(field.Name)
-				if f.CanAddr() && f.Addr().CanInterface() {
-					value := f.Addr().Interface()
-					if err := parseType(value, fullName, visibility, handler); err != nil {
-						return err
-					}
-				} else {
-					if f.Kind() == reflect.Ptr {
-						f = f.Elem()
-					}
-					if (f.Kind() == reflect.Struct) && (f.Type() == tVariable) {
-						fmt.Println("warning: Variable is unexported or unadressable", fullName)
+				f := tValue.Field(i)
+				if field.PkgPath != "" {
+					// unexported field
+					if containsVariable(field.Type) {
+						if err := p.ignore(fullName, "Variable is unexported"); err != nil {
+							return err
+						}
 					}
+					continue
+				}
+				pop := p.push(name)
+				err := p.parse(f, fullName, visibility)
+				pop()
+				if err != nil {
+					return err
 				}
 			}
 		}
 
 	case reflect.Slice, reflect.Array:
 		if tValue.Len() == 0 {
-			fmt.Println("warning, got unitizalized slice (or empty array). Ignoring;")
+			if containsVariable(tValue.Type()) {
+				return p.ignore(baseName, "empty slice (or array)")
+			}
 			return nil
 		}
 		for j := 0; j < tValue.Len(); j++ {
-
-			val := tValue.Index(j)
-			if val.CanAddr() && val.Addr().CanInterface() {
-				if err := parseType(val.Addr().Interface(), appendName(baseName, strconv.Itoa(j)), parentVisibility, handler); err != nil {
-					return err
-				}
+			pop := p.push(strconv.Itoa(j))
+			err := p.parse(tValue.Index(j), appendName(baseName, strconv.Itoa(j)), parentVisibility)
+			pop()
+			if err != nil {
+				return err
 			}
-
 		}
+
 	case reflect.Map:
-		fmt.Println("warning: map values are not addressable, ignoring")
+		if tValue.Len() == 0 {
+			if containsVariable(tValue.Type()) {
+				return p.ignore(baseName, "empty map")
+			}
+			return nil
+		}
+		keys := tValue.MapKeys()
+		sortKeys(keys)
+		for _, key := range keys {
+			// map values are not addressable, we parse a copy that we write back
+			elem := fmt.Sprint(key.Interface())
+			pop := p.push(elem)
+			err := p.parseCopy(tValue.MapIndex(key), appendName(baseName, elem), parentVisibility, func(v reflect.Value) error {
+				tValue.SetMapIndex(key, v)
+				return nil
+			})
+			pop()
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// parseCopy parses an addressable copy of v, and calls writeBack with it once parsed
+func (p *typeParser) parseCopy(v reflect.Value, baseName string, parentVisibility backend.Visibility, writeBack func(reflect.Value) error) error {
+	cpy := reflect.New(v.Type()).Elem()
+	cpy.Set(v)
+	if err := p.parse(cpy, baseName, parentVisibility); err != nil {
+		return err
+	}
+	return writeBack(cpy)
+}
+
+// containsVariable returns true if values of type t hold a Variable
+// (interfaces are not resolved and don't count)
+func containsVariable(t reflect.Type) bool {
+	return _containsVariable(t, make(map[reflect.Type]struct{}))
+}
+
+func _containsVariable(t reflect.Type, visited map[reflect.Type]struct{}) bool {
+	if _, ok := visited[t]; ok {
+		return false
+	}
+	visited[t] = struct{}{}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == tVariable {
+			return true
+		}
+		if t == tConstraintSystem {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if _containsVariable(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return _containsVariable(t.Elem(), visited)
+	}
+	return false
+}
+
+// sortKeys sorts map keys, numerically if they are integers and by their string representation otherwise
+func sortKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		default:
+			return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+		}
+	})
+}
+
 func appendName(baseName, name string) string {
 	if baseName == "" {
 		return name
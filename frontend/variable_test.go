@@ -1,12 +1,16 @@
 package frontend
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"log"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gurvy"
 )
 
 func TestStructTags(t *testing.T) {
@@ -161,3 +165,108 @@ func TestStructTags(t *testing.T) {
 	}
 
 }
+
+type mapCircuit struct {
+	M map[string]Variable `gnark:",public"`
+	N map[int]struct {
+		X Variable
+	}
+	P *Variable
+	I interface{}
+}
+
+func (c *mapCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(cs.Add(c.N[2].X, c.N[10].X, *c.P, c.I.(Variable)), cs.Mul(c.M["a"], c.M["b"]))
+	return nil
+}
+
+func newMapCircuit() *mapCircuit {
+	return &mapCircuit{
+		M: map[string]Variable{"b": {}, "a": {}},
+		N: map[int]struct{ X Variable }{10: {}, 2: {}},
+		P: new(Variable),
+		I: Variable{},
+	}
+}
+
+func TestParseTypeMapsPointersInterfaces(t *testing.T) {
+	var names []string
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		names = append(names, name)
+		return nil
+	}
+	if err := parseTypeStrict(newMapCircuit(), "", backend.Unset, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"M_a", "M_b", "N_2_X", "N_10_X", "P", "I"}) {
+		t.Fatal("unexpected names", names)
+	}
+
+	// the allocated Variables are written back in the maps and interfaces
+	circuit := newMapCircuit()
+	r1cs, err := Compile(gurvy.BN256, circuit, WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	witness := newMapCircuit()
+	assign := func(v Variable, value int) Variable {
+		v.Assign(value)
+		return v
+	}
+	witness.M["a"], witness.M["b"] = assign(witness.M["a"], 3), assign(witness.M["b"], 4)
+	witness.N[2], witness.N[10] = struct{ X Variable }{assign(Variable{}, 2)}, struct{ X Variable }{assign(Variable{}, 5)}
+	witness.P.Assign(1)
+	witness.I = assign(witness.I.(Variable), 4)
+	assignment, err := ParseWitnessStrict(witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r1cs.IsSolved(assignment); err != nil {
+		t.Fatal(err)
+	}
+
+	// unassigned inputs are reported in strict mode
+	witness.P = new(Variable)
+	if _, err := ParseWitnessStrict(witness); !errors.Is(err, backend.ErrInputNotSet) {
+		t.Fatal("expected ErrInputNotSet, got", err)
+	}
+}
+
+func TestParseTypeStrict(t *testing.T) {
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		return nil
+	}
+
+	// the ignored Variables are reported to Logger
+	var warnings bytes.Buffer
+	defer func(logger *log.Logger) { Logger = logger }(Logger)
+	Logger = log.New(&warnings, "", 0)
+
+	for name, input := range map[string]interface{}{
+		"unexported": &struct {
+			A Variable
+			b Variable
+		}{},
+		"nil pointer": &struct {
+			A *Variable
+		}{},
+		"empty slice": &struct {
+			A []Variable
+		}{},
+		"empty map": &struct {
+			A map[string]Variable
+		}{},
+	} {
+		warnings.Reset()
+		if err := parseType(input, "", backend.Unset, handler); err != nil {
+			t.Fatal(name, "should be ignored, got", err)
+		}
+		if !strings.HasPrefix(warnings.String(), "warning: ") {
+			t.Fatal(name, "should be logged, got", warnings.String())
+		}
+		if err := parseTypeStrict(input, "", backend.Unset, handler); err == nil {
+			t.Fatal(name, "should be reported in strict mode")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gurvy"
+)
+
+// InputNames returns the names of the secret and public inputs of the circuit, as resolved by Compile
+// (see type Tag), in the order of the wires Compile allocates for them. The ONE wire is not included
+func InputNames(circuit Circuit) (secret, public []string, err error) {
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		switch visibility {
+		case backend.Unset, backend.Secret:
+			secret = append(secret, name)
+		case backend.Public:
+			public = append(public, name)
+		}
+		return nil
+	}
+	err = parseType(circuit, "", backend.Unset, handler)
+	return
+}
+
+// AssignWitness assigns the inputs of the circuit from assignment (map[input name]value), the input
+// names being resolved as Compile does (see type Tag), such that circuit can be used as a witness
+//
+// it returns an error naming the offending input if a value is missing, if a value is not in the
+// scalar field of curveID or if the circuit has no input matching an entry of assignment.
+// Values must be convertible to big.Int (see backend.FromInterface)
+func AssignWitness(circuit Circuit, assignment map[string]interface{}, curveID gurvy.ID) error {
+	modulus := r1cs.FieldModulus(curveID)
+	assigned := make(map[string]struct{}, len(assignment))
+
+	var handler leafHandler = func(visibility backend.Visibility, name string, tInput reflect.Value) error {
+		v := tInput.Addr().Interface().(*Variable)
+		if v.val != nil {
+			return fmt.Errorf("%q: variable already assigned", name)
+		}
+		val, ok := assignment[name]
+		if !ok {
+			return fmt.Errorf("%q: %w", name, backend.ErrInputNotSet)
+		}
+		b := backend.FromInterface(val)
+		if b.Sign() == -1 || b.Cmp(modulus) != -1 {
+			return fmt.Errorf("%q: value is not in the scalar field of %s", name, curveID.String())
+		}
+		v.Assign(b)
+		assigned[name] = struct{}{}
+		return nil
+	}
+	if err := parseType(circuit, "", backend.Unset, handler); err != nil {
+		return err
+	}
+
+	if len(assigned) != len(assignment) {
+		var extra []string
+		for name := range assignment {
+			if _, ok := assigned[name]; !ok {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		return fmt.Errorf("%q: %w", extra[0], errNotAnInput)
+	}
+
+	return nil
+}
+
+var errNotAnInput = errors.New("not an input of the circuit")
+
+// ReadWitness decodes a JSON witness (see gnark/io.WriteWitness) from reader and assigns the inputs
+// of the circuit with it (see AssignWitness)
+func ReadWitness(reader io.Reader, circuit Circuit, curveID gurvy.ID) error {
+	assignment := make(map[string]interface{})
+	if err := gnarkio.ReadWitness(reader, assignment); err != nil {
+		return err
+	}
+	return AssignWitness(circuit, assignment, curveID)
+}
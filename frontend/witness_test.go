@@ -0,0 +1,95 @@
+package frontend
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gurvy"
+)
+
+type witnessCircuit struct {
+	A     Variable `gnark:"a,public"`
+	Inner struct {
+		B [2]Variable
+		C Variable
+	}
+}
+
+func (c *witnessCircuit) Define(curveID gurvy.ID, cs *ConstraintSystem) error {
+	cs.AssertIsEqual(cs.Add(c.Inner.B[0], c.Inner.B[1], c.Inner.C), c.A)
+	return nil
+}
+
+func TestInputNames(t *testing.T) {
+	secret, public, err := InputNames(&witnessCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(secret, []string{"Inner_B_0", "Inner_B_1", "Inner_C"}) || !reflect.DeepEqual(public, []string{"a"}) {
+		t.Fatal("unexpected input names", secret, public)
+	}
+}
+
+func TestAssignWitness(t *testing.T) {
+	assignment := func() map[string]interface{} {
+		return map[string]interface{}{"a": 6, "Inner_B_0": 1, "Inner_B_1": 2, "Inner_C": 3}
+	}
+
+	// JSON round trip into the circuit struct
+	var buf bytes.Buffer
+	if err := gnarkio.WriteWitness(&buf, assignment()); err != nil {
+		t.Fatal(err)
+	}
+	var witness witnessCircuit
+	if err := ReadWitness(&buf, &witness, gurvy.BN256); err != nil {
+		t.Fatal(err)
+	}
+	var circuit witnessCircuit
+	res, err := Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_witness, err := ParseWitness(&witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.IsSolved(_witness); err != nil {
+		t.Fatal(err)
+	}
+
+	// errors name the offending input
+	missing := assignment()
+	delete(missing, "Inner_B_1")
+	extra := assignment()
+	extra["Inner_D"] = 4
+	outOfField := assignment()
+	outOfField["Inner_C"] = r1cs.FieldModulus(gurvy.BN256)
+	negative := assignment()
+	negative["a"] = big.NewInt(-1)
+
+	for _, tc := range []struct {
+		assignment map[string]interface{}
+		input      string
+	}{
+		{missing, "Inner_B_1"},
+		{extra, "Inner_D"},
+		{outOfField, "Inner_C"},
+		{negative, "a"},
+	} {
+		var witness witnessCircuit
+		err := AssignWitness(&witness, tc.assignment, gurvy.BN256)
+		if err == nil || !strings.Contains(err.Error(), "\""+tc.input+"\"") {
+			t.Fatal("expected an error naming", tc.input, "got", err)
+		}
+	}
+	if err := AssignWitness(&witnessCircuit{}, missing, gurvy.BN256); !errors.Is(err, backend.ErrInputNotSet) {
+		t.Fatal("expected ErrInputNotSet, got", err)
+	}
+}
@@ -53,6 +53,15 @@ func (proof *Proof) GetCurveID() gurvy.ID {
 // if force flag is set, Prove ignores R1CS solving error (ie invalid solution) and executes
 // the FFTs and MultiExponentiations to compute an (invalid) Proof object
 func Prove(r1cs *bls377backend.R1CS, pk *ProvingKey, solution map[string]interface{}, force bool) (*Proof, error) {
+	var witness bls377backend.Witness
+	if err := witness.FromAssignment(r1cs, solution); err != nil && !force {
+		return nil, err
+	}
+	return ProveWitness(r1cs, pk, &witness, force)
+}
+
+// ProveWitness is like Prove, but takes the inputs from a typed Witness
+func ProveWitness(r1cs *bls377backend.R1CS, pk *ProvingKey, witness *bls377backend.Witness, force bool) (*Proof, error) {
 	nbPrivateWires := r1cs.NbWires - r1cs.NbPublicWires
 
 	// solve the R1CS and compute the a, b, c vectors
@@ -60,7 +69,7 @@ func Prove(r1cs *bls377backend.R1CS, pk *ProvingKey, solution map[string]interfa
 	b := make([]fr.Element, r1cs.NbConstraints, pk.Domain.Cardinality)
 	c := make([]fr.Element, r1cs.NbConstraints, pk.Domain.Cardinality)
 	wireValues := make([]fr.Element, r1cs.NbWires)
-	if err := r1cs.Solve(solution, a, b, c, wireValues); err != nil && !force {
+	if err := r1cs.SolveWitness(witness, a, b, c, wireValues); err != nil && !force {
 		return nil, err
 	}
 
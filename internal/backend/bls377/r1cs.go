@@ -20,13 +20,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
+	"sync"
+	"sync/atomic"
 
 	"github.com/fxamacker/cbor/v2"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/backend/r1cs/r1c"
 	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/consensys/gnark/internal/utils"
+	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gurvy"
 
@@ -49,6 +55,19 @@ type R1CS struct {
 	NbCOConstraints uint64 // number of constraints that need to be solved, the first of the Constraints slice
 	Constraints     []r1c.R1C
 	Coefficients    []fr.Element // R1C coefficients indexes point here
+
+	// Hints
+	Hints []r1c.Hint // wires computed by hint functions, not tied to a constraint
+
+	// Components
+	Components []r1c.Component // boundaries of the sub-circuits in the constraints
+
+	// Call stacks
+	CallStacks   [][]string // unique call stacks of the constraints
+	CallStackIDs []int      // index in CallStacks of the call stack of each constraint (-1 if none), empty if no stack was recorded
+
+	// Solver
+	Schedule *r1c.Schedule `cbor:"-"` // dependency levels of the hints and computational constraints, computed by the compiler or when reading the R1CS
 }
 
 // GetNbConstraints returns the total number of constraints
@@ -66,27 +85,46 @@ func (r1cs *R1CS) GetNbCoefficients() int {
 	return len(r1cs.Coefficients)
 }
 
+// GetComponents returns the sub-circuits boundaries in the constraints
+func (r1cs *R1CS) GetComponents() []r1c.Component {
+	return r1cs.Components
+}
+
+// GetInputNames returns the names of the secret and public inputs, ordered as the wires
+// (the ONE wire is the first public input)
+func (r1cs *R1CS) GetInputNames() (secret, public []string) {
+	return r1cs.SecretWires, r1cs.PublicWires
+}
+
 // GetCurveID returns curve ID as defined in gurvy (gurvy.BLS377)
 func (r1cs *R1CS) GetCurveID() gurvy.ID {
 	return gurvy.BLS377
 }
 
-// WriteTo encodes R1CS into provided io.Writer using cbor
+// WriteTo encodes R1CS into provided io.Writer using cbor, in a gnark container (see gnarkio.WriteContainer)
 func (r1cs *R1CS) WriteTo(w io.Writer) (int64, error) {
-	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
-	encoder := cbor.NewEncoder(&_w)
-
-	// encode our object
-	err := encoder.Encode(r1cs)
-	return _w.N, err
+	return gnarkio.WriteContainer(w, gurvy.BLS377, gnarkio.ObjectR1CS, func(w io.Writer) (int64, error) {
+		_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+		encoder := cbor.NewEncoder(&_w)
+
+		// encode our object
+		err := encoder.Encode(r1cs)
+		return _w.N, err
+	})
 }
 
 // ReadFrom attempts to decode R1CS from io.Reader using cbor
+// the data must be a gnark container holding a R1CS for this curve (see gnarkio.ReadContainer)
 func (r1cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
-	decoder := cbor.NewDecoder(r)
+	return gnarkio.ReadContainer(r, gurvy.BLS377, gnarkio.ObjectR1CS, func(r io.Reader) (int64, error) {
+		decoder := cbor.NewDecoder(r)
 
-	err := decoder.Decode(r1cs)
-	return int64(decoder.NumBytesRead()), err
+		if err := decoder.Decode(r1cs); err != nil {
+			return int64(decoder.NumBytesRead()), err
+		}
+		r1cs.Schedule = r1c.NewSchedule(int(r1cs.NbWires), int(r1cs.NbWires-r1cs.NbPublicWires-r1cs.NbSecretWires), r1cs.Constraints[:r1cs.NbCOConstraints], r1cs.Hints)
+		return int64(decoder.NumBytesRead()), nil
+	})
 }
 
 // IsSolved returns nil if given assignment solves the R1CS and error otherwise
@@ -99,12 +137,38 @@ func (r1cs *R1CS) IsSolved(assignment map[string]interface{}) error {
 	return r1cs.Solve(assignment, a, b, c, wireValues)
 }
 
+// Solution solves the R1CS and returns the values of all its wires, internal wires included
+func (r1cs *R1CS) Solution(assignment map[string]interface{}) (*backend.Solution, error) {
+	a := make([]fr.Element, r1cs.NbConstraints)
+	b := make([]fr.Element, r1cs.NbConstraints)
+	c := make([]fr.Element, r1cs.NbConstraints)
+	wireValues := make([]fr.Element, r1cs.NbWires)
+	if err := r1cs.Solve(assignment, a, b, c, wireValues); err != nil {
+		return nil, err
+	}
+
+	solution := backend.NewSolution(int(r1cs.NbWires-r1cs.NbPublicWires-r1cs.NbSecretWires), r1cs.SecretWires, r1cs.PublicWires)
+	for i := 0; i < len(wireValues); i++ {
+		wireValues[i].ToBigIntRegular(&solution.Values[i])
+	}
+	return solution, nil
+}
+
 // Solve sets all the wires and returns the a, b, c vectors.
 // the r1cs system should have been compiled before. The entries in a, b, c are in Montgomery form.
 // assignment: map[string]value: contains the input variables
 // a, b, c vectors: ab-c = hz
 // wireValues =  [intermediateVariables | privateInputs | publicInputs]
 func (r1cs *R1CS) Solve(assignment map[string]interface{}, a, b, c, wireValues []fr.Element) error {
+	var witness Witness
+	if err := witness.FromAssignment(r1cs, assignment); err != nil {
+		return err
+	}
+	return r1cs.SolveWitness(&witness, a, b, c, wireValues)
+}
+
+// SolveWitness is like Solve, but takes the inputs from a typed Witness, without map lookups
+func (r1cs *R1CS) SolveWitness(witness *Witness, a, b, c, wireValues []fr.Element) error {
 	// compute the wires and the a, b, c polynomials
 	if len(a) != int(r1cs.NbConstraints) || len(b) != int(r1cs.NbConstraints) || len(c) != int(r1cs.NbConstraints) || len(wireValues) != int(r1cs.NbWires) {
 		return errors.New("invalid input size: len(a, b, c) == r1cs.NbConstraints and len(wireValues) == r1cs.NbWires")
@@ -114,38 +178,36 @@ func (r1cs *R1CS) Solve(assignment map[string]interface{}, a, b, c, wireValues [
 	wireInstantiated := make([]bool, r1cs.NbWires)
 
 	// instantiate the public/ private inputs
-	// note that currently, there is a convertion from interface{} to fr.Element for each entry in the
-	// assignment map. It can cost a SetBigInt() which converts from Regular ton Montgomery rep (1 mul)
-	// while it's unlikely to be noticeable compared to the FFT and the MultiExp compute times,
-	// there should be a faster (statically typed) path
-	instantiateInputs := func(offset int, inputNames []string) error {
+	instantiateInputs := func(offset int, inputNames []string, values []fr.Element) error {
 		for i := 0; i < len(inputNames); i++ {
-			name := inputNames[i]
-			if name == backend.OneWire {
+			if inputNames[i] == backend.OneWire {
 				wireValues[i+offset].SetOne()
 				wireInstantiated[i+offset] = true
-			} else {
-				if val, ok := assignment[name]; ok {
-					wireValues[i+offset].SetInterface(val)
-					wireInstantiated[i+offset] = true
-				} else {
-					return fmt.Errorf("%q: %w", name, backend.ErrInputNotSet)
-				}
+				continue
+			}
+			if len(values) == 0 {
+				return fmt.Errorf("%q: %w", inputNames[i], backend.ErrInputNotSet)
 			}
+			wireValues[i+offset] = values[0]
+			wireInstantiated[i+offset] = true
+			values = values[1:]
+		}
+		if len(values) != 0 {
+			return errors.New("invalid witness size: too many inputs")
 		}
 		return nil
 	}
 	// instantiate private inputs
-	if r1cs.NbSecretWires != 0 {
+	{
 		offset := int(r1cs.NbWires - r1cs.NbPublicWires - r1cs.NbSecretWires) // private input start index
-		if err := instantiateInputs(offset, r1cs.SecretWires); err != nil {
+		if err := instantiateInputs(offset, r1cs.SecretWires, witness.Secret); err != nil {
 			return err
 		}
 	}
 	// instantiate public inputs
 	{
 		offset := int(r1cs.NbWires - r1cs.NbPublicWires) // public input start index
-		if err := instantiateInputs(offset, r1cs.PublicWires); err != nil {
+		if err := instantiateInputs(offset, r1cs.PublicWires, witness.Public); err != nil {
 			return err
 		}
 	}
@@ -154,51 +216,194 @@ func (r1cs *R1CS) Solve(assignment map[string]interface{}, a, b, c, wireValues [
 	// (or sooner, if a constraint is not satisfied)
 	defer r1cs.printLogs(wireValues, wireInstantiated)
 
-	// check if there is an inconsistant constraint
-	var check fr.Element
+	schedule := r1cs.Schedule
+	if schedule == nil {
+		// the R1CS was not built by the compiler or read from a reader
+		schedule = r1c.NewSchedule(int(r1cs.NbWires), int(r1cs.NbWires-r1cs.NbPublicWires-r1cs.NbSecretWires), r1cs.Constraints[:r1cs.NbCOConstraints], r1cs.Hints)
+	}
 
-	// Loop through computational constraints (the one wwe need to solve and compute a wire in)
-	for i := 0; i < int(r1cs.NbCOConstraints); i++ {
+	// the failure which comes first in the sequential solving order
+	failure := newSolverFailure()
+
+	// solve the hints and the computational constraints (the one we need to solve and compute a wire in), level by level:
+	// the items of a level only depend on the wires computed in the previous levels
+	for l := 0; l < len(schedule.Levels); l++ {
+		level := &schedule.Levels[l]
+		nbHints := len(level.Hints)
+		parallelize(nbHints+len(level.Constraints), func(start, end int) {
+			var check fr.Element
+			for j := start; j < end; j++ {
+				if j < nbHints {
+					i := level.Hints[j]
+					if failure.skip(schedule.HintSeq[i]) {
+						continue
+					}
+					if err := r1cs.solveHint(&r1cs.Hints[i], wireInstantiated, wireValues); err != nil {
+						failure.record(schedule.HintSeq[i], -1, err)
+					}
+					continue
+				}
+
+				i := level.Constraints[j-nbHints]
+				if failure.skip(schedule.ConstraintSeq[i]) {
+					continue
+				}
 
-		// solve the constraint, this will compute the missing wire of the gate
-		r1cs.solveR1C(&r1cs.Constraints[i], wireInstantiated, wireValues)
+				// solve the constraint, this will compute the missing wire of the gate
+				r1cs.solveR1C(&r1cs.Constraints[i], wireInstantiated, wireValues)
 
-		// at this stage we are guaranteed that a[i]*b[i]=c[i]
-		// if not, it means there is a bug in the solver
-		a[i], b[i], c[i] = instantiateR1C(&r1cs.Constraints[i], r1cs, wireValues)
+				// at this stage a[i]*b[i]=c[i] holds, unless the witness is invalid and the
+				// constraint has no solution (for example, a binary decomposition of a value out of range)
+				a[i], b[i], c[i] = instantiateR1C(&r1cs.Constraints[i], r1cs, wireValues)
 
-		check.Mul(&a[i], &b[i])
-		if !check.Equal(&c[i]) {
-			panic("error solving r1c: " + a[i].String() + "*" + b[i].String() + "=" + c[i].String())
+				check.Mul(&a[i], &b[i])
+				if !check.Equal(&c[i]) {
+					failure.record(schedule.ConstraintSeq[i], i, nil)
+				}
+			}
+		})
+	}
+
+	if failure.failed() {
+		// the wires computed after the failure in the sequential order were not solved
+		for wireID, seq := range schedule.WireSeq {
+			if failure.skip(seq) {
+				wireInstantiated[wireID] = false
+			}
 		}
+		return r1cs.solverError(failure, a, b, c, wireValues, wireInstantiated)
 	}
 
-	// Loop through the assertions -- here all wireValues should be instantiated
+	// check the assertions -- here all wireValues should be instantiated
 	// if a[i] * b[i] != c[i]; it means the constraint is not satisfied
-	for i := int(r1cs.NbCOConstraints); i < len(r1cs.Constraints); i++ {
-
-		// A this stage we are not guaranteed that a[i+sizecg]*b[i+sizecg]=c[i+sizecg] because we only query the values (computed
-		// at the previous step)
-		a[i], b[i], c[i] = instantiateR1C(&r1cs.Constraints[i], r1cs, wireValues)
-
-		// check that the constraint is satisfied
-		check.Mul(&a[i], &b[i])
-		if !check.Equal(&c[i]) {
-			debugInfo := r1cs.DebugInfo[i-int(r1cs.NbCOConstraints)]
-			debugInfoStr := r1cs.logValue(debugInfo, wireValues, wireInstantiated)
-			return fmt.Errorf("%w: %s", backend.ErrUnsatisfiedConstraint, debugInfoStr)
+	offset := int(r1cs.NbCOConstraints)
+	parallelize(len(r1cs.Constraints)-offset, func(start, end int) {
+		var check fr.Element
+		for i := start + offset; i < end+offset; i++ {
+			if failure.skip(i) {
+				continue
+			}
+
+			// A this stage we are not guaranteed that a[i]*b[i]=c[i] because we only query the values (computed
+			// at the previous step)
+			a[i], b[i], c[i] = instantiateR1C(&r1cs.Constraints[i], r1cs, wireValues)
+
+			// check that the constraint is satisfied
+			check.Mul(&a[i], &b[i])
+			if !check.Equal(&c[i]) {
+				failure.record(i, i, nil)
+			}
 		}
+	})
+
+	if failure.failed() {
+		return r1cs.solverError(failure, a, b, c, wireValues, wireInstantiated)
 	}
 
 	return nil
 }
 
+// minParallelItems is the minimum number of items to solve or check before it is worth spawning goroutines
+const minParallelItems = 64
+
+// parallelize calls work on [0, n), concurrently if n is large enough
+func parallelize(n int, work func(start, end int)) {
+	if n < minParallelItems {
+		work(0, n)
+		return
+	}
+	utils.Parallelize(n, work)
+}
+
+// solverFailure keeps the failing item which comes first in the sequential solving order
+// (see r1c.Schedule), the items coming after it can be skipped
+type solverFailure struct {
+	seq          int64 // sequence number of the failing item, accessed atomically
+	lock         sync.Mutex
+	constraintID int   // index of the unsatisfied constraint, -1 if a hint failed
+	err          error // error returned by the hint
+}
+
+func newSolverFailure() *solverFailure {
+	return &solverFailure{seq: math.MaxInt64}
+}
+
+func (f *solverFailure) failed() bool {
+	return f.seq != math.MaxInt64
+}
+
+// skip returns true if a failure was recorded before the item with sequence number seq
+func (f *solverFailure) skip(seq int) bool {
+	return int64(seq) > atomic.LoadInt64(&f.seq)
+}
+
+func (f *solverFailure) record(seq, constraintID int, err error) {
+	f.lock.Lock()
+	if int64(seq) < f.seq {
+		atomic.StoreInt64(&f.seq, int64(seq))
+		f.constraintID = constraintID
+		f.err = err
+	}
+	f.lock.Unlock()
+}
+
+// solverError returns the error of the recorded failure
+func (r1cs *R1CS) solverError(f *solverFailure, a, b, c, wireValues []fr.Element, wireInstantiated []bool) error {
+	if f.constraintID == -1 {
+		return f.err
+	}
+	i := f.constraintID
+	return r1cs.unsatisfiedConstraint(i, a[i], b[i], c[i], wireValues, wireInstantiated)
+}
+
+// unsatisfiedConstraint returns a *backend.UnsatisfiedConstraintError describing the i-th constraint,
+// where a*b != c
+func (r1cs *R1CS) unsatisfiedConstraint(i int, a, b, c fr.Element, wireValues []fr.Element, wireInstantiated []bool) error {
+	err := &backend.UnsatisfiedConstraintError{
+		ConstraintID: i,
+		Assertion:    i >= int(r1cs.NbCOConstraints),
+		L:            a.String(),
+		R:            b.String(),
+		O:            c.String(),
+		Wires:        make(map[int]string),
+	}
+
+	// debug info may have been stripped at compile time
+	if err.Assertion && len(r1cs.DebugInfo) != 0 {
+		err.DebugInfo = r1cs.logValue(r1cs.DebugInfo[i-int(r1cs.NbCOConstraints)], wireValues, wireInstantiated)
+	}
+
+	r := &r1cs.Constraints[i]
+	for _, le := range [3]r1c.LinearExpression{r.L, r.R, r.O} {
+		for _, t := range le {
+			wireID := t.VariableID()
+			if wireInstantiated[wireID] {
+				err.Wires[wireID] = wireValues[wireID].String()
+			} else {
+				err.Wires[wireID] = "<unsolved>"
+			}
+		}
+	}
+
+	if len(r1cs.CallStackIDs) != 0 && r1cs.CallStackIDs[i] != -1 {
+		err.Stack = r1cs.CallStacks[r1cs.CallStackIDs[i]]
+	}
+
+	if id := r1c.FindComponent(r1cs.Components, i); id != -1 {
+		err.Component = r1c.ComponentPath(r1cs.Components, id)
+	}
+
+	return err
+}
+
 func (r1cs *R1CS) logValue(entry backend.LogEntry, wireValues []fr.Element, wireInstantiated []bool) string {
 	var toResolve []interface{}
 	for j := 0; j < len(entry.ToResolve); j++ {
 		wireID := entry.ToResolve[j]
 		if !wireInstantiated[wireID] {
-			panic("wire values was not instantiated")
+			// solving stopped (for example, a hint function returned an error) before this wire was computed
+			toResolve = append(toResolve, "<unsolved>")
+			continue
 		}
 		toResolve = append(toResolve, wireValues[wireID].String())
 	}
@@ -271,6 +476,37 @@ func instantiateR1C(r *r1c.R1C, r1cs *R1CS, wireValues []fr.Element) (a, b, c fr
 	return
 }
 
+// solveHint evaluates the inputs of the hint, calls the hint function and instantiates the wire it computes
+func (r1cs *R1CS) solveHint(h *r1c.Hint, wireInstantiated []bool, wireValues []fr.Element) error {
+	f, ok := hint.Lookup(h.ID)
+	if !ok {
+		return fmt.Errorf("%w (id %d)", hint.ErrNotRegistered, h.ID)
+	}
+
+	inputs := make([]*big.Int, len(h.Inputs))
+	for i, le := range h.Inputs {
+		var v fr.Element
+		for _, t := range le {
+			cID := t.VariableID()
+			if !wireInstantiated[cID] {
+				return errors.New("hint input is not instantiated")
+			}
+			r1cs.AddTerm(&v, t, wireValues[cID])
+		}
+		inputs[i] = new(big.Int)
+		v.ToBigIntRegular(inputs[i])
+	}
+
+	var result big.Int
+	if err := f(fr.Modulus(), inputs, &result); err != nil {
+		return err
+	}
+	wireValues[h.WireID].SetBigInt(&result)
+	wireInstantiated[h.WireID] = true
+
+	return nil
+}
+
 // solveR1c computes a wire by solving a r1cs
 // the function searches for the unset wire (either the unset wire is
 // alone, or it can be computed without ambiguity using the other computed wires
@@ -24,6 +24,7 @@ import (
 	bls381backend "github.com/consensys/gnark/internal/backend/bls381"
 
 	"bytes"
+	"errors"
 	"github.com/fxamacker/cbor/v2"
 	"testing"
 
@@ -137,6 +138,49 @@ func TestReferenceCircuit(t *testing.T) {
 	assert.ProverSucceeded(r1cs, solution)
 }
 
+func TestVerifySecretInputs(t *testing.T) {
+	circuit := refCircuit{
+		nbConstraints: 2,
+	}
+	r1cs, err := frontend.Compile(curve.ID, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_r1cs := r1cs.(*bls381backend.R1CS)
+	solution := map[string]interface{}{"X": 2, "Y": 16}
+	publicSolution := map[string]interface{}{"Y": 16}
+
+	var pk bls381groth16.ProvingKey
+	var vk bls381groth16.VerifyingKey
+	bls381groth16.Setup(_r1cs, &pk, &vk)
+	proof, err := bls381groth16.Prove(_r1cs, &pk, solution, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bls381groth16.Verify(proof, &vk, publicSolution); err != nil {
+		t.Fatal(err)
+	}
+	// the secret inputs of a map are ignored
+	if err := bls381groth16.Verify(proof, &vk, solution); err != nil {
+		t.Fatal(err)
+	}
+
+	var public, full bls381backend.Witness
+	if err := public.FromPublicAssignment(_r1cs, solution); err != nil {
+		t.Fatal(err)
+	}
+	if err := bls381groth16.VerifyWitness(proof, &vk, &public); err != nil {
+		t.Fatal(err)
+	}
+	if err := full.FromAssignment(_r1cs, solution); err != nil {
+		t.Fatal(err)
+	}
+	if err := bls381groth16.VerifyWitness(proof, &vk, &full); !errors.Is(err, backend.ErrSecretInPublicWitness) {
+		t.Fatal("expected ErrSecretInPublicWitness, got", err)
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	r1cs, _ := referenceCircuit()
 
@@ -19,6 +19,8 @@ package backend_test
 import (
 	bls381backend "github.com/consensys/gnark/internal/backend/bls381"
 
+	"github.com/consensys/gurvy/bls381/fr"
+
 	"bytes"
 	"github.com/consensys/gnark/internal/backend/circuits"
 	"github.com/consensys/gurvy"
@@ -59,3 +61,44 @@ func TestSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestWitnessSerialization(t *testing.T) {
+	var witness bls381backend.Witness
+	witness.Secret = make([]fr.Element, 3)
+	witness.Public = make([]fr.Element, 2)
+	for i := 0; i < len(witness.Secret); i++ {
+		witness.Secret[i].SetRandom()
+	}
+	for i := 0; i < len(witness.Public); i++ {
+		witness.Public[i].SetRandom()
+	}
+
+	var buffer bytes.Buffer
+	written, err := witness.WriteTo(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(8+5*fr.Bytes) {
+		t.Fatal("unexpected witness size", written)
+	}
+	encoded := append([]byte{}, buffer.Bytes()...)
+
+	var reconstructed bls381backend.Witness
+	read, err := reconstructed.ReadFrom(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != read {
+		t.Fatal("didn't read same number of bytes we wrote")
+	}
+	if !reflect.DeepEqual(witness, reconstructed) {
+		t.Fatal("round trip serialization failed")
+	}
+
+	// values must be canonical
+	modulus := fr.Modulus().Bytes()
+	copy(encoded[8+fr.Bytes-len(modulus):], modulus)
+	if _, err := reconstructed.ReadFrom(bytes.NewReader(encoded)); err == nil {
+		t.Fatal("expected an error when reading a value out of the field")
+	}
+}
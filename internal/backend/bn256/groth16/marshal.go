@@ -22,6 +22,9 @@ import (
 	"encoding/binary"
 	"github.com/fxamacker/cbor/v2"
 	"io"
+
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gurvy"
 )
 
 // WriteTo writes binary encoding of the Proof elements to writer
@@ -92,7 +95,13 @@ func (vk *VerifyingKey) WriteRawTo(w io.Writer) (n int64, err error) {
 	return vk.writeTo(w, true)
 }
 
-func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (n int64, err error) {
+func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	return gnarkio.WriteContainer(w, gurvy.BN256, gnarkio.ObjectVerifyingKey, func(w io.Writer) (int64, error) {
+		return vk.writePayload(w, raw)
+	})
+}
+
+func (vk *VerifyingKey) writePayload(w io.Writer, raw bool) (n int64, err error) {
 	var written int
 
 	// encode public input names
@@ -145,10 +154,15 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (n int64, err error) {
 }
 
 // ReadFrom attempts to decode a VerifyingKey from reader
-// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed),
+// in a gnark container (see gnarkio.ReadContainer)
 // note that we don't check that the points are on the curve or in the correct subgroup at this point
 // TODO while Proof points correctness is checkd in the Verifier, here may be a good place to check key
-func (vk *VerifyingKey) ReadFrom(r io.Reader) (n int64, err error) {
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	return gnarkio.ReadContainer(r, gurvy.BN256, gnarkio.ObjectVerifyingKey, vk.readPayload)
+}
+
+func (vk *VerifyingKey) readPayload(r io.Reader) (n int64, err error) {
 
 	var read int
 	var buf [curve.SizeOfGT]byte
@@ -218,6 +232,12 @@ func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
 }
 
 func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	return gnarkio.WriteContainer(w, gurvy.BN256, gnarkio.ObjectProvingKey, func(w io.Writer) (int64, error) {
+		return pk.writePayload(w, raw)
+	})
+}
+
+func (pk *ProvingKey) writePayload(w io.Writer, raw bool) (int64, error) {
 	n, err := pk.Domain.WriteTo(w)
 	if err != nil {
 		return n, err
@@ -254,10 +274,15 @@ func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 }
 
 // ReadFrom attempts to decode a ProvingKey from reader
-// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed),
+// in a gnark container (see gnarkio.ReadContainer)
 // note that we don't check that the points are on the curve or in the correct subgroup at this point
 // TODO while Proof points correctness is checkd in the Verifier, here may be a good place to check key
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	return gnarkio.ReadContainer(r, gurvy.BN256, gnarkio.ObjectProvingKey, pk.readPayload)
+}
+
+func (pk *ProvingKey) readPayload(r io.Reader) (int64, error) {
 
 	n, err := pk.Domain.ReadFrom(r)
 	if err != nil {
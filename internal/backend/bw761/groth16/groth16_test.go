@@ -24,6 +24,7 @@ import (
 	bw761backend "github.com/consensys/gnark/internal/backend/bw761"
 
 	"bytes"
+	"errors"
 	"github.com/fxamacker/cbor/v2"
 	"testing"
 
@@ -137,6 +138,49 @@ func TestReferenceCircuit(t *testing.T) {
 	assert.ProverSucceeded(r1cs, solution)
 }
 
+func TestVerifySecretInputs(t *testing.T) {
+	circuit := refCircuit{
+		nbConstraints: 2,
+	}
+	r1cs, err := frontend.Compile(curve.ID, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_r1cs := r1cs.(*bw761backend.R1CS)
+	solution := map[string]interface{}{"X": 2, "Y": 16}
+	publicSolution := map[string]interface{}{"Y": 16}
+
+	var pk bw761groth16.ProvingKey
+	var vk bw761groth16.VerifyingKey
+	bw761groth16.Setup(_r1cs, &pk, &vk)
+	proof, err := bw761groth16.Prove(_r1cs, &pk, solution, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw761groth16.Verify(proof, &vk, publicSolution); err != nil {
+		t.Fatal(err)
+	}
+	// the secret inputs of a map are ignored
+	if err := bw761groth16.Verify(proof, &vk, solution); err != nil {
+		t.Fatal(err)
+	}
+
+	var public, full bw761backend.Witness
+	if err := public.FromPublicAssignment(_r1cs, solution); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw761groth16.VerifyWitness(proof, &vk, &public); err != nil {
+		t.Fatal(err)
+	}
+	if err := full.FromAssignment(_r1cs, solution); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw761groth16.VerifyWitness(proof, &vk, &full); !errors.Is(err, backend.ErrSecretInPublicWitness) {
+		t.Fatal("expected ErrSecretInPublicWitness, got", err)
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	r1cs, _ := referenceCircuit()
 
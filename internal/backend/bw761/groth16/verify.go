@@ -21,7 +21,10 @@ import (
 
 	curve "github.com/consensys/gurvy/bw761"
 
+	bw761backend "github.com/consensys/gnark/internal/backend/bw761"
+
 	"errors"
+	"fmt"
 	"github.com/consensys/gnark/backend"
 )
 
@@ -31,7 +34,44 @@ var (
 )
 
 // Verify verifies a proof
+//
+// inputs that are not public inputs of vk are ignored
 func Verify(proof *Proof, vk *VerifyingKey, inputs map[string]interface{}) error {
+	kInputs, err := ParsePublicInput(vk.PublicInputs, inputs)
+	if err != nil {
+		return err
+	}
+	return verify(proof, vk, kInputs)
+}
+
+// VerifyWitness is like Verify, but takes the public inputs from a typed Witness;
+// it returns backend.ErrSecretInPublicWitness if the witness is not public
+func VerifyWitness(proof *Proof, vk *VerifyingKey, witness *bw761backend.Witness) error {
+	if !witness.IsPublic() {
+		return backend.ErrSecretInPublicWitness
+	}
+	kInputs := make([]fr.Element, len(vk.PublicInputs))
+	public := witness.Public
+	for i := 0; i < len(vk.PublicInputs); i++ {
+		if vk.PublicInputs[i] == backend.OneWire {
+			kInputs[i].SetOne()
+		} else {
+			if len(public) == 0 {
+				return fmt.Errorf("%q: %w", vk.PublicInputs[i], backend.ErrInputNotSet)
+			}
+			kInputs[i] = public[0]
+			public = public[1:]
+		}
+		kInputs[i].FromMont()
+	}
+	if len(public) != 0 {
+		return errors.New("invalid witness size: too many public inputs")
+	}
+	return verify(proof, vk, kInputs)
+}
+
+// verify verifies a proof, given the public inputs in regular form (see ParsePublicInput)
+func verify(proof *Proof, vk *VerifyingKey, kInputs []fr.Element) error {
 
 	// check that the points in the proof are in the correct subgroup
 	if !proof.isValid() {
@@ -61,10 +101,6 @@ func Verify(proof *Proof, vk *VerifyingKey, inputs map[string]interface{}) error
 
 	// compute e(Σx.[Kvk(t)]1, -[γ]2)
 	var kSum curve.G1Affine
-	kInputs, err := ParsePublicInput(vk.PublicInputs, inputs)
-	if err != nil {
-		return err
-	}
 	kSum.MultiExp(vk.G1.K, kInputs)
 
 	right, err := curve.MillerLoop([]curve.G1Affine{kSum}, []curve.G2Affine{vk.G2.GammaNeg})
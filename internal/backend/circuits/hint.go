@@ -0,0 +1,48 @@
+package circuits
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type hintCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+// modSqrt is a hint computing a square root of inputs[0]
+func modSqrt(modulus *big.Int, inputs []*big.Int, result *big.Int) error {
+	if result.ModSqrt(inputs[0], modulus) == nil {
+		return errors.New("input is not a square")
+	}
+	return nil
+}
+
+func (circuit *hintCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	x2 := cs.Mul(circuit.X, circuit.X)
+	s := cs.NewHint(modSqrt, x2) // either X or -X
+	cs.AssertIsEqual(cs.Mul(s, s), x2)
+	cs.AssertIsEqual(cs.Mul(s, s, s, s), circuit.Y)
+	return nil
+}
+
+func init() {
+	var circuit, good, bad, public hintCircuit
+	r1cs, err := frontend.Compile(gurvy.UNKNOWN, &circuit)
+	if err != nil {
+		panic(err)
+	}
+
+	good.X.Assign(3)
+	good.Y.Assign(81)
+
+	bad.X.Assign(3)
+	bad.Y.Assign(82)
+
+	public.Y.Assign(81)
+
+	addEntry("hint", r1cs, &good, &bad, &public)
+}
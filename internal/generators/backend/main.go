@@ -61,6 +61,9 @@ func main() {
 			if err := bgen.GenerateF(d, "backend", "./template/representations/", bavard.EntryF{
 				File:      filepath.Join(backendDir, "r1cs.go"),
 				TemplateF: []string{"r1cs.go.tmpl", importCurve},
+			}, bavard.EntryF{
+				File:      filepath.Join(backendDir, "witness.go"),
+				TemplateF: []string{"witness.go.tmpl", importCurve},
 			}); err != nil {
 				panic(err)
 			}
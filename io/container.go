@@ -0,0 +1,234 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	"github.com/consensys/gurvy"
+)
+
+// Object is the type of a gnark object in a container (see WriteContainer)
+type Object uint8
+
+// types of the objects written in containers
+const (
+	ObjectR1CS Object = iota + 1
+	ObjectProvingKey
+	ObjectVerifyingKey
+)
+
+func (o Object) String() string {
+	switch o {
+	case ObjectR1CS:
+		return "R1CS"
+	case ObjectProvingKey:
+		return "proving key"
+	case ObjectVerifyingKey:
+		return "verifying key"
+	default:
+		return fmt.Sprintf("unknown object (%d)", uint8(o))
+	}
+}
+
+// Magic starts every container
+var Magic = [4]byte{'g', 'n', 'r', 'k'}
+
+// FormatVersion is the version of the container format written by this version of gnark
+const FormatVersion uint16 = 1
+
+// HeaderSize is the size in bytes of an encoded Header
+const HeaderSize = 4 + 2 + 2 + 1 + 8 + sha256.Size
+
+// Header describes the payload of a container
+//
+// it is encoded as Magic | Version | CurveID | Object | PayloadSize | Checksum, in big endian
+type Header struct {
+	Version     uint16
+	CurveID     gurvy.ID // gurvy.UNKNOWN for an untyped R1CS
+	Object      Object
+	PayloadSize uint64
+	Checksum    [sha256.Size]byte // sha256 of the payload
+}
+
+var (
+	// ErrNoHeader is returned when the data doesn't start with Magic
+	ErrNoHeader = errors.New("no gnark header")
+	// ErrUnsupportedVersion is returned when the container format version is not FormatVersion
+	ErrUnsupportedVersion = errors.New("unsupported format version")
+	// ErrCurveMismatch is returned when the container holds an object typed for another curve
+	ErrCurveMismatch = errors.New("curve mismatch")
+	// ErrObjectMismatch is returned when the container holds another type of object
+	ErrObjectMismatch = errors.New("object type mismatch")
+	// ErrChecksum is returned when the payload doesn't match the checksum of the header
+	ErrChecksum = errors.New("checksum mismatch")
+)
+
+// WriteContainer writes a Header, then the payload written by writePayload
+//
+// the payload is not held in memory: writePayload is called twice, first to compute the size and checksum
+// of the payload, then to write it (it must write the same bytes both times). The number of bytes written
+// includes the header
+func WriteContainer(w io.Writer, curveID gurvy.ID, object Object, writePayload func(w io.Writer) (int64, error)) (int64, error) {
+	hasher := &hashCounter{hash: sha256.New()}
+	if _, err := writePayload(hasher); err != nil {
+		return 0, err
+	}
+
+	header := Header{
+		Version:     FormatVersion,
+		CurveID:     curveID,
+		Object:      object,
+		PayloadSize: uint64(hasher.n),
+	}
+	hasher.hash.Sum(header.Checksum[:0])
+	buf := make([]byte, 0, HeaderSize)
+	buf = append(buf, Magic[:]...)
+	buf = append(buf, byte(header.Version>>8), byte(header.Version))
+	buf = append(buf, byte(header.CurveID>>8), byte(header.CurveID))
+	buf = append(buf, byte(header.Object))
+	buf = append(buf, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], header.PayloadSize)
+	buf = append(buf, header.Checksum[:]...)
+
+	n, err := w.Write(buf)
+	if err != nil {
+		return int64(n), err
+	}
+
+	// the payload is hashed again as it is written, to make sure it matches the header
+	hasher = &hashCounter{hash: sha256.New()}
+	writer := &writerCounter{w: io.MultiWriter(w, hasher)}
+	if _, err := writePayload(writer); err != nil {
+		return int64(n) + writer.n, err
+	}
+	if !hasher.matches(header) {
+		return int64(n) + writer.n, fmt.Errorf("the %s changed while it was written", object)
+	}
+	return int64(n) + writer.n, nil
+}
+
+// ReadHeader reads the Header of a container
+func ReadHeader(r io.Reader) (Header, error) {
+	var header Header
+	var buf [HeaderSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return header, fmt.Errorf("%w: data is too short; it may have been written by a version of gnark older than the container format, re-serialize it with this version", ErrNoHeader)
+		}
+		return header, err
+	}
+	if !bytes.Equal(buf[:len(Magic)], Magic[:]) {
+		return header, fmt.Errorf("%w: it may have been written by a version of gnark older than the container format, re-serialize it with this version", ErrNoHeader)
+	}
+	b := buf[len(Magic):]
+	header.Version = binary.BigEndian.Uint16(b[0:2])
+	header.CurveID = gurvy.ID(binary.BigEndian.Uint16(b[2:4]))
+	header.Object = Object(b[4])
+	header.PayloadSize = binary.BigEndian.Uint64(b[5:13])
+	copy(header.Checksum[:], b[13:])
+	return header, nil
+}
+
+// ReadContainer reads a container written by WriteContainer, checks its header and the checksum of
+// its payload, then calls readPayload on the payload
+//
+// the number of bytes read includes the header
+func ReadContainer(r io.Reader, curveID gurvy.ID, object Object, readPayload func(r io.Reader) (int64, error)) (int64, error) {
+	header, err := ReadHeader(r)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case header.Version > FormatVersion:
+		return HeaderSize, fmt.Errorf("%w: version %d was written by a newer version of gnark, this one reads version %d", ErrUnsupportedVersion, header.Version, FormatVersion)
+	case header.Version != FormatVersion:
+		return HeaderSize, fmt.Errorf("%w: version %d is no longer supported, this version of gnark reads version %d; re-serialize the object with it", ErrUnsupportedVersion, header.Version, FormatVersion)
+	case header.Object != object:
+		return HeaderSize, fmt.Errorf("%w: data holds a %s, not a %s", ErrObjectMismatch, header.Object, object)
+	case header.CurveID != curveID:
+		return HeaderSize, fmt.Errorf("%w: data holds a %s for curve %s, not %s", ErrCurveMismatch, object, curveName(header.CurveID), curveName(curveID))
+	}
+
+	// the payload is hashed as it is decoded; the decoder can't read past its end
+	hasher := &hashCounter{hash: sha256.New()}
+	payload := io.TeeReader(io.LimitReader(r, int64(header.PayloadSize)), hasher)
+	_, errPayload := readPayload(payload)
+
+	// hash what the decoder left, if anything, to check the whole payload
+	if _, err := io.Copy(ioutil.Discard, payload); err != nil {
+		return HeaderSize + hasher.n, err
+	}
+	switch {
+	case uint64(hasher.n) != header.PayloadSize:
+		return HeaderSize + hasher.n, fmt.Errorf("%w: the %s is truncated", ErrChecksum, object)
+	case !hasher.matches(header):
+		return HeaderSize + hasher.n, fmt.Errorf("%w: the %s is corrupted", ErrChecksum, object)
+	case errPayload != nil:
+		return HeaderSize + hasher.n, errPayload
+	}
+	return HeaderSize + hasher.n, nil
+}
+
+// hashCounter hashes and counts the bytes written to it
+type hashCounter struct {
+	hash hash.Hash
+	n    int64
+}
+
+func (h *hashCounter) Write(p []byte) (int, error) {
+	n, err := h.hash.Write(p)
+	h.n += int64(n)
+	return n, err
+}
+
+// matches returns true if the bytes written match the size and checksum of header
+func (h *hashCounter) matches(header Header) bool {
+	var checksum [sha256.Size]byte
+	h.hash.Sum(checksum[:0])
+	return uint64(h.n) == header.PayloadSize && checksum == header.Checksum
+}
+
+// writerCounter counts the bytes written to w
+type writerCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *writerCounter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func curveName(curveID gurvy.ID) string {
+	switch curveID {
+	case gurvy.BN256, gurvy.BLS377, gurvy.BLS381, gurvy.BW761:
+		return curveID.String()
+	case gurvy.UNKNOWN:
+		return "unknown (untyped)"
+	default:
+		return fmt.Sprintf("#%d", uint16(curveID))
+	}
+}
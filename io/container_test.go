@@ -0,0 +1,100 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/consensys/gurvy"
+)
+
+func TestContainer(t *testing.T) {
+	data := []byte("payload")
+	writePayload := func(w io.Writer) (int64, error) {
+		n, err := w.Write(data)
+		return int64(n), err
+	}
+	var read []byte
+	readPayload := func(r io.Reader) (int64, error) {
+		var err error
+		read, err = ioutil.ReadAll(r)
+		return int64(len(read)), err
+	}
+
+	var buf bytes.Buffer
+	written, err := WriteContainer(&buf, gurvy.BN256, ObjectProvingKey, writePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(HeaderSize+len(data)) || buf.Len() != int(written) {
+		t.Fatal("unexpected size", written)
+	}
+	encoded := append([]byte{}, buf.Bytes()...)
+
+	header, err := ReadHeader(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != FormatVersion || header.CurveID != gurvy.BN256 || header.Object != ObjectProvingKey || header.PayloadSize != uint64(len(data)) {
+		t.Fatal("unexpected header", header)
+	}
+
+	n, err := ReadContainer(&buf, gurvy.BN256, ObjectProvingKey, readPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != written || !bytes.Equal(read, data) {
+		t.Fatal("round trip failed")
+	}
+
+	check := func(data []byte, curveID gurvy.ID, object Object, expected error) {
+		t.Helper()
+		if _, err := ReadContainer(bytes.NewReader(data), curveID, object, readPayload); !errors.Is(err, expected) {
+			t.Fatal("expected", expected, "got", err)
+		}
+	}
+	check(encoded, gurvy.BLS381, ObjectProvingKey, ErrCurveMismatch)
+	check(encoded, gurvy.BN256, ObjectVerifyingKey, ErrObjectMismatch)
+	check(data, gurvy.BN256, ObjectProvingKey, ErrNoHeader)
+	check(encoded[:len(encoded)-1], gurvy.BN256, ObjectProvingKey, ErrChecksum)
+
+	corrupted := append([]byte{}, encoded...)
+	corrupted[len(corrupted)-1] ^= 1
+	check(corrupted, gurvy.BN256, ObjectProvingKey, ErrChecksum)
+
+	newer := append([]byte{}, encoded...)
+	newer[len(Magic)+1]++
+	check(newer, gurvy.BN256, ObjectProvingKey, ErrUnsupportedVersion)
+
+	// the decoder can't read past the payload
+	followed := append(append([]byte{}, encoded...), "next"...)
+	reader := bytes.NewReader(followed)
+	if _, err := ReadContainer(reader, gurvy.BN256, ObjectProvingKey, readPayload); err != nil || !bytes.Equal(read, data) {
+		t.Fatal("unexpected payload", string(read), err)
+	}
+	if reader.Len() != len("next") {
+		t.Fatal("read past the container")
+	}
+
+	// the whole payload is checked, even if the decoder doesn't read all of it
+	readPrefix := func(r io.Reader) (int64, error) {
+		n, err := io.ReadFull(r, make([]byte, 2))
+		return int64(n), err
+	}
+	if _, err := ReadContainer(bytes.NewReader(corrupted), gurvy.BN256, ObjectProvingKey, readPrefix); !errors.Is(err, ErrChecksum) {
+		t.Fatal("expected", ErrChecksum, "got", err)
+	}
+
+	// writePayload must write the same bytes twice
+	calls := 0
+	unstable := func(w io.Writer) (int64, error) {
+		calls++
+		n, err := w.Write(data[:calls])
+		return int64(n), err
+	}
+	if _, err := WriteContainer(ioutil.Discard, gurvy.BN256, ObjectProvingKey, unstable); err == nil {
+		t.Fatal("expected an error for a payload which changed while it was written")
+	}
+}
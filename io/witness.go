@@ -11,7 +11,7 @@ import (
 	"github.com/consensys/gnark/backend"
 )
 
-// TODO this is deprecated, we might need a type Witness = map[string]interface{}
+// note: package backend/witness provides a curve-typed Witness, with a compact binary encoding
 
 // WriteWitness serialize variable map[name]value into writer
 //
@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/fuzz"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gurvy"
@@ -136,3 +139,54 @@ func TestMimcBLS377(t *testing.T) {
 	assert.SolvingSucceeded(r1cs, &witness)
 
 }
+
+func TestMimcFuzz(t *testing.T) {
+	var circuit mimcCircuit
+	r1cs, err := frontend.Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := func(data fr_bn256.Element) (fr_bn256.Element, error) {
+		dataBytes := data.Bytes()
+		b, err := mimcbn256.Sum("seed", dataBytes[:])
+		if err != nil {
+			return fr_bn256.Element{}, err
+		}
+		var res fr_bn256.Element
+		res.SetBytes(b)
+		return res, nil
+	}
+
+	// random elements of the whole field
+	element := gen.SliceOfN(32, gen.UInt8()).Map(func(b []uint8) fr_bn256.Element {
+		var e fr_bn256.Element
+		e.SetBytes(b)
+		return e
+	})
+
+	// the expected result is either the hash of the data, or an independent random element
+	gens := map[string]gopter.Gen{
+		"Data":   element,
+		"data":   element,
+		"honest": gen.Bool(),
+	}
+	derive := func(assignment map[string]interface{}) error {
+		if !assignment["honest"].(bool) {
+			return nil
+		}
+		h, err := hash(assignment["Data"].(fr_bn256.Element))
+		if err != nil {
+			return err
+		}
+		assignment["data"] = h
+		return nil
+	}
+	reference := func(assignment map[string]interface{}) bool {
+		expected, err := hash(assignment["Data"].(fr_bn256.Element))
+		data := assignment["data"].(fr_bn256.Element)
+		return err == nil && expected.Equal(&data)
+	}
+
+	fuzz.Check(t, r1cs, gens, reference, fuzz.WithDerivedInputs(derive))
+}
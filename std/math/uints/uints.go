@@ -0,0 +1,201 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package uints implements fixed-width unsigned integers (uint32, uint64, ...) in a circuit
+//
+// A Word is stored as its bit decomposition, which is computed once (see NewWord).
+// Shifts and rotations are then free, bitwise operations cost at most one constraint per bit
+// and a modular addition costs one bit decomposition.
+package uints
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Word is an unsigned integer of fixed width in a circuit
+type Word struct {
+	Bits []frontend.Variable // little endian (Bits[0] is the least significant bit), each bit is boolean
+}
+
+// NewWord decomposes v in nbBits bits and returns the corresponding Word
+//
+// this adds nbBits+1 constraints to cs, and ensures v fits in nbBits bits
+func NewWord(cs *frontend.ConstraintSystem, v frontend.Variable, nbBits int) Word {
+	return Word{Bits: cs.ToBinary(v, nbBits)}
+}
+
+// NewWordConstant returns the Word of width nbBits holding the constant c (no constraint is recorded)
+func NewWordConstant(cs *frontend.ConstraintSystem, c uint64, nbBits int) Word {
+	w := Word{Bits: make([]frontend.Variable, nbBits)}
+	for i := 0; i < nbBits; i++ {
+		if i < 64 {
+			w.Bits[i] = cs.Constant((c >> i) & 1)
+		} else {
+			w.Bits[i] = cs.Constant(0)
+		}
+	}
+	return w
+}
+
+// Width returns the number of bits of w
+func (w *Word) Width() int {
+	return len(w.Bits)
+}
+
+// Value packs the bits of w and returns the corresponding Variable (no constraint is recorded)
+func (w *Word) Value(cs *frontend.ConstraintSystem) frontend.Variable {
+	var coeff big.Int
+	coeff.SetUint64(2)
+
+	res := cs.Mul(w.Bits[0], 1) // no constraint is recorded
+	for i := 1; i < len(w.Bits); i++ {
+		res = cs.Add(res, cs.Mul(coeff, w.Bits[i])) // no constraint is recorded
+		coeff.Lsh(&coeff, 1)
+	}
+	return res
+}
+
+// And sets w = a & b and returns w (1 constraint per bit)
+func (w *Word) And(cs *frontend.ConstraintSystem, a, b Word) *Word {
+	checkWidths(a, b)
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		res[i] = cs.Mul(a.Bits[i], b.Bits[i])
+	}
+	w.Bits = res
+	return w
+}
+
+// Or sets w = a | b and returns w (1 constraint per bit)
+func (w *Word) Or(cs *frontend.ConstraintSystem, a, b Word) *Word {
+	checkWidths(a, b)
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		// a + b - a*b
+		ab := cs.Mul(a.Bits[i], b.Bits[i])
+		res[i] = cs.Sub(cs.Add(a.Bits[i], b.Bits[i]), ab) // no constraint is recorded
+	}
+	w.Bits = res
+	return w
+}
+
+// Xor sets w = a ^ b and returns w (1 constraint per bit)
+func (w *Word) Xor(cs *frontend.ConstraintSystem, a, b Word) *Word {
+	checkWidths(a, b)
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		// a + b - 2*a*b
+		ab := cs.Mul(a.Bits[i], b.Bits[i])
+		res[i] = cs.Sub(cs.Add(a.Bits[i], b.Bits[i]), cs.Mul(ab, 2)) // no constraint is recorded
+	}
+	w.Bits = res
+	return w
+}
+
+// Not sets w = ^a and returns w (no constraint is recorded)
+func (w *Word) Not(cs *frontend.ConstraintSystem, a Word) *Word {
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		res[i] = cs.Sub(1, a.Bits[i])
+	}
+	w.Bits = res
+	return w
+}
+
+// Shl sets w = a << n and returns w (no constraint is recorded)
+func (w *Word) Shl(cs *frontend.ConstraintSystem, a Word, n int) *Word {
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		if i < n {
+			res[i] = cs.Constant(0)
+		} else {
+			res[i] = a.Bits[i-n]
+		}
+	}
+	w.Bits = res
+	return w
+}
+
+// Shr sets w = a >> n and returns w (no constraint is recorded)
+func (w *Word) Shr(cs *frontend.ConstraintSystem, a Word, n int) *Word {
+	res := make([]frontend.Variable, len(a.Bits))
+	for i := 0; i < len(res); i++ {
+		if i+n < len(res) {
+			res[i] = a.Bits[i+n]
+		} else {
+			res[i] = cs.Constant(0)
+		}
+	}
+	w.Bits = res
+	return w
+}
+
+// Rotl sets w to a rotated left by n bits and returns w (no constraint is recorded)
+func (w *Word) Rotl(cs *frontend.ConstraintSystem, a Word, n int) *Word {
+	width := len(a.Bits)
+	n = ((n % width) + width) % width
+	res := make([]frontend.Variable, width)
+	for i := 0; i < width; i++ {
+		res[(i+n)%width] = a.Bits[i]
+	}
+	w.Bits = res
+	return w
+}
+
+// Rotr sets w to a rotated right by n bits and returns w (no constraint is recorded)
+func (w *Word) Rotr(cs *frontend.ConstraintSystem, a Word, n int) *Word {
+	return w.Rotl(cs, a, -n)
+}
+
+// Add sets w = a + b + (others...) mod 2^width and returns w
+//
+// the sum is decomposed once, in width + log2(number of operands) bits
+func (w *Word) Add(cs *frontend.ConstraintSystem, a, b Word, others ...Word) *Word {
+	checkWidths(a, b, others...)
+
+	sum := cs.Add(a.Value(cs), b.Value(cs)) // no constraint is recorded
+	for i := 0; i < len(others); i++ {
+		sum = cs.Add(sum, others[i].Value(cs)) // no constraint is recorded
+	}
+
+	// the sum of k words of n bits fits in n + bits.Len(k-1) bits
+	nbCarries := bits.Len(uint(len(others) + 1))
+	sumBits := cs.ToBinary(sum, len(a.Bits)+nbCarries)
+
+	w.Bits = sumBits[:len(a.Bits)]
+	return w
+}
+
+// MustBeEqual adds an assertion in cs ensuring a == w
+func (w *Word) MustBeEqual(cs *frontend.ConstraintSystem, a Word) {
+	checkWidths(*w, a)
+	cs.AssertIsEqual(w.Value(cs), a.Value(cs))
+}
+
+// checkWidths panics if the words don't have the same width
+func checkWidths(a, b Word, others ...Word) {
+	if len(a.Bits) != len(b.Bits) {
+		panic("words have different widths")
+	}
+	for i := 0; i < len(others); i++ {
+		if len(others[i].Bits) != len(a.Bits) {
+			panic("words have different widths")
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gurvy"
+)
+
+type wordCircuit struct {
+	A, B, C                         frontend.Variable
+	And, Or, Xor, Not               frontend.Variable `gnark:",public"`
+	Shl, Shr, Rotl, Rotr, Sigma, Ch frontend.Variable `gnark:",public"`
+	Sum, Sum3                       frontend.Variable `gnark:",public"`
+}
+
+func (circuit *wordCircuit) Define(curveID gurvy.ID, cs *frontend.ConstraintSystem) error {
+	a := NewWord(cs, circuit.A, 32)
+	b := NewWord(cs, circuit.B, 32)
+	c := NewWord(cs, circuit.C, 32)
+
+	var w Word
+	cs.AssertIsEqual(w.And(cs, a, b).Value(cs), circuit.And)
+	cs.AssertIsEqual(w.Or(cs, a, b).Value(cs), circuit.Or)
+	cs.AssertIsEqual(w.Xor(cs, a, b).Value(cs), circuit.Xor)
+	cs.AssertIsEqual(w.Not(cs, a).Value(cs), circuit.Not)
+	cs.AssertIsEqual(w.Shl(cs, a, 7).Value(cs), circuit.Shl)
+	cs.AssertIsEqual(w.Shr(cs, a, 7).Value(cs), circuit.Shr)
+	cs.AssertIsEqual(w.Rotl(cs, a, 7).Value(cs), circuit.Rotl)
+	cs.AssertIsEqual(w.Rotr(cs, a, 7).Value(cs), circuit.Rotr)
+
+	// sha256 Σ0 and Ch functions
+	var r2, r13, r22, sigma Word
+	r2.Rotr(cs, a, 2)
+	r13.Rotr(cs, a, 13)
+	r22.Rotr(cs, a, 22)
+	sigma.Xor(cs, r2, r13).Xor(cs, sigma, r22)
+	cs.AssertIsEqual(sigma.Value(cs), circuit.Sigma)
+
+	var ab, nac, ch Word
+	ab.And(cs, a, b)
+	nac.Not(cs, a).And(cs, nac, c)
+	ch.Xor(cs, ab, nac)
+	cs.AssertIsEqual(ch.Value(cs), circuit.Ch)
+
+	cs.AssertIsEqual(w.Add(cs, a, b).Value(cs), circuit.Sum)
+	cs.AssertIsEqual(w.Add(cs, a, b, c, NewWordConstant(cs, 0xffffffff, 32)).Value(cs), circuit.Sum3)
+
+	return nil
+}
+
+func (circuit *wordCircuit) assign(a, b, c uint32) {
+	circuit.A.Assign(uint64(a))
+	circuit.B.Assign(uint64(b))
+	circuit.C.Assign(uint64(c))
+	circuit.And.Assign(uint64(a & b))
+	circuit.Or.Assign(uint64(a | b))
+	circuit.Xor.Assign(uint64(a ^ b))
+	circuit.Not.Assign(uint64(^a))
+	circuit.Shl.Assign(uint64(a << 7))
+	circuit.Shr.Assign(uint64(a >> 7))
+	circuit.Rotl.Assign(uint64(bits.RotateLeft32(a, 7)))
+	circuit.Rotr.Assign(uint64(bits.RotateLeft32(a, -7)))
+	circuit.Sigma.Assign(uint64(bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)))
+	circuit.Ch.Assign(uint64((a & b) ^ (^a & c)))
+	circuit.Sum.Assign(uint64(a + b))
+	circuit.Sum3.Assign(uint64(a + b + c + 0xffffffff))
+}
+
+func TestWord(t *testing.T) {
+	var circuit wordCircuit
+	r1cs, err := frontend.Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := groth16.NewAssert(t)
+	for i := 0; i < 10; i++ {
+		a, b, c := rand.Uint32(), rand.Uint32(), rand.Uint32()
+
+		var witness wordCircuit
+		witness.assign(a, b, c)
+		assert.SolvingSucceeded(r1cs, &witness)
+		if err := frontend.IsSolved(&wordCircuit{}, &witness, gurvy.BN256); err != nil {
+			t.Fatal(err)
+		}
+
+		var bad wordCircuit
+		bad.assign(a, b, c)
+		bad.Sum3 = frontend.Variable{}
+		bad.Sum3.Assign(uint64(a + b + c))
+		assert.SolvingFailed(r1cs, &bad)
+		if err := frontend.IsSolved(&wordCircuit{}, &bad, gurvy.BN256); err == nil {
+			t.Fatal("the test engine should fail with an incorrect sum")
+		}
+	}
+
+}
@@ -17,15 +17,21 @@ limitations under the License.
 package eddsa
 
 import (
+	"encoding/binary"
+	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/fuzz"
 	mimc_bn256 "github.com/consensys/gnark/crypto/hash/mimc/bn256"
 	eddsa_bn256 "github.com/consensys/gnark/crypto/signature/eddsa/bn256"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
 	"github.com/consensys/gurvy"
 	fr_bn256 "github.com/consensys/gurvy/bn256/fr"
+	edwards_bn256 "github.com/consensys/gurvy/bn256/twistededwards"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
 )
 
 type eddsaCircuit struct {
@@ -97,6 +103,11 @@ func TestEddsa(t *testing.T) {
 		witness.Signature.S.Assign(signature.S)
 
 		assert.SolvingSucceeded(r1cs, &witness)
+
+		// the test engine executes Define without compiling the circuit
+		if err := frontend.IsSolved(&eddsaCircuit{}, &witness, gurvy.BN256); err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	// verification with incorrect Message
@@ -113,5 +124,77 @@ func TestEddsa(t *testing.T) {
 		witness.Signature.S.Assign(signature.S)
 
 		assert.SolvingFailed(r1cs, &witness)
+
+		if err := frontend.IsSolved(&eddsaCircuit{}, &witness, gurvy.BN256); err == nil {
+			t.Fatal("the test engine should fail with an incorrect message")
+		}
+	}
+}
+
+func TestEddsaFuzz(t *testing.T) {
+	var circuit eddsaCircuit
+	r1cs, err := frontend.Compile(gurvy.BN256, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a signature of message with a key generated from seed, then corrupted:
+	// 0 is a valid signature, 1 shifts S, 2 shifts the message, 3 shifts the public key
+	gens := map[string]gopter.Gen{
+		"seed":    gen.UInt64(),
+		"message": gen.UInt64(),
+		"corrupt": gen.UInt64Range(0, 3),
+	}
+	derive := func(assignment map[string]interface{}) error {
+		var seed [32]byte
+		binary.BigEndian.PutUint64(seed[:], assignment["seed"].(uint64))
+		pubKey, privKey := eddsa_bn256.New(seed, mimc_bn256.NewMiMC("seed"))
+
+		var msg fr_bn256.Element
+		msg.SetUint64(assignment["message"].(uint64))
+		msgBin := msg.Bytes()
+		signature, err := eddsa_bn256.Sign(msgBin[:], pubKey, privKey)
+		if err != nil {
+			return err
+		}
+
+		var one fr_bn256.Element
+		one.SetOne()
+		switch assignment["corrupt"].(uint64) {
+		case 1:
+			signature.S.Add(&signature.S, big.NewInt(1))
+		case 2:
+			msg.Add(&msg, &one)
+		case 3:
+			pubKey.A.X.Add(&pubKey.A.X, &one)
+		}
+
+		assignment["PublicKey_A_X"] = pubKey.A.X
+		assignment["PublicKey_A_Y"] = pubKey.A.Y
+		assignment["Signature_R_A_X"] = signature.R.X
+		assignment["Signature_R_A_Y"] = signature.R.Y
+		assignment["Signature_S"] = signature.S
+		assignment["Message"] = msg
+		return nil
+	}
+	reference := func(assignment map[string]interface{}) bool {
+		var pubKey eddsa_bn256.PublicKey
+		pubKey.A = edwards_bn256.Point{X: assignment["PublicKey_A_X"].(fr_bn256.Element), Y: assignment["PublicKey_A_Y"].(fr_bn256.Element)}
+		pubKey.HFunc = mimc_bn256.NewMiMC("seed")
+
+		var signature eddsa_bn256.Signature
+		signature.R = edwards_bn256.Point{X: assignment["Signature_R_A_X"].(fr_bn256.Element), Y: assignment["Signature_R_A_Y"].(fr_bn256.Element)}
+		signature.S = assignment["Signature_S"].(big.Int)
+
+		msg := assignment["Message"].(fr_bn256.Element)
+		msgBin := msg.Bytes()
+		res, err := eddsa_bn256.Verify(signature, msgBin[:], pubKey)
+		return err == nil && res
+	}
+
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = 10
 	}
+	fuzz.Check(t, r1cs, gens, reference, fuzz.WithDerivedInputs(derive), fuzz.WithTestParameters(parameters))
 }